@@ -1,9 +1,18 @@
 package dagsterpipes
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
 // MessageChannel represents an interface for writing messages.
@@ -15,11 +24,93 @@ type MessageChannel interface {
 	Close() error
 }
 
+// ContextMessageChannel is implemented by channels whose writes honor the
+// cancellation and deadline of a context.
+type ContextMessageChannel interface {
+	// WriteMessageContext writes a Message to the underlying channel, giving up
+	// when the context is canceled or its deadline is exceeded.
+	WriteMessageContext(ctx context.Context, message Message) error
+}
+
+// ContextCloser is implemented by channels whose Close honors the cancellation
+// and deadline of a context, e.g. while flushing buffered messages.
+type ContextCloser interface {
+	// CloseContext closes the underlying channel, giving up pending work when
+	// the context is canceled or its deadline is exceeded.
+	CloseContext(ctx context.Context) error
+}
+
+// deadlineWriter is implemented by writers supporting write deadlines, such as
+// pipes and network connections.
+type deadlineWriter interface {
+	SetWriteDeadline(t time.Time) error
+}
+
+// ErrPartialWrite indicates that a message was only partially written, e.g.
+// because the write was aborted at a deadline. The channel rejects further
+// writes, which would be appended to the truncated line and corrupt the stream.
+var ErrPartialWrite = errors.New("partial message write")
+
+// writeLine writes a newline-terminated message to w like writeContext. After a
+// partial write, failed is set and all further writes return it.
+func writeLine(ctx context.Context, w io.Writer, data []byte, failed *error) error {
+	if *failed != nil {
+		return *failed
+	}
+
+	n, err := writeContext(ctx, w, data)
+	if err != nil && n > 0 {
+		*failed = fmt.Errorf("%w: %d of %d bytes written: %w", ErrPartialWrite, n, len(data), err)
+	}
+
+	return err
+}
+
+// writeContext writes data to w and returns the number of bytes written. If w
+// supports write deadlines, the write is bounded by the deadline of ctx and
+// aborted when ctx is canceled. Other writers are only checked for cancellation
+// before the write.
+func writeContext(ctx context.Context, w io.Writer, data []byte) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	dw, ok := w.(deadlineWriter)
+	if !ok || ctx.Done() == nil {
+		return w.Write(data)
+	}
+
+	deadline, _ := ctx.Deadline()
+	if err := dw.SetWriteDeadline(deadline); err != nil {
+		// Regular files do not support deadlines.
+		return w.Write(data)
+	}
+
+	defer func() {
+		_ = dw.SetWriteDeadline(time.Time{})
+	}()
+
+	stop := context.AfterFunc(ctx, func() {
+		_ = dw.SetWriteDeadline(time.Now())
+	})
+	defer stop()
+
+	n, err := w.Write(data)
+	if err != nil && errors.Is(err, os.ErrDeadlineExceeded) {
+		// The deadline is only set from ctx, which is done or about to be done.
+		<-ctx.Done()
+		return n, ctx.Err()
+	}
+
+	return n, err
+}
+
 // FileMessageWriterChannel implements the MessageChannel interface.
 // It writes messages to a specified file.
 type FileMessageWriterChannel struct {
-	mu   sync.Mutex // Protects concurrent access to the file handle.
-	file *os.File   // Open file handle for writing messages.
+	mu     sync.Mutex // Protects concurrent access to the file handle.
+	file   *os.File   // Open file handle for writing messages.
+	failed error      // Set after a partial write, rejects further writes.
 }
 
 // NewFileMessageWriterChannel creates a new FileMessageWriterChannel.
@@ -49,9 +140,21 @@ func (f *FileMessageWriterChannel) WriteMessage(message Message) error {
 	defer f.mu.Unlock()
 
 	// Write the JSON data to the file with a newline.
-	_, err = f.file.Write(append(data, '\n'))
+	return writeLine(context.Background(), f.file, append(data, '\n'), &f.failed)
+}
 
-	return err
+// WriteMessageContext writes a Message like WriteMessage. The write is bounded by
+// the deadline of the context if the file is a pipe.
+func (f *FileMessageWriterChannel) WriteMessageContext(ctx context.Context, message Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return writeLine(ctx, f.file, append(data, '\n'), &f.failed)
 }
 
 // Close closes the underlying file handle. Should be called to release resources.
@@ -62,3 +165,212 @@ func (f *FileMessageWriterChannel) Close() error {
 
 	return f.file.Close()
 }
+
+// StreamMessageWriterChannel implements the MessageChannel interface.
+// It writes messages to a stream such as os.Stderr or os.Stdout.
+type StreamMessageWriterChannel struct {
+	mu     sync.Mutex // Protects concurrent access to the stream.
+	stream io.Writer  // Stream the messages are written to.
+	failed error      // Set after a partial write, rejects further writes.
+}
+
+// NewStreamMessageWriterChannel creates a new StreamMessageWriterChannel writing to the given stream.
+func NewStreamMessageWriterChannel(stream io.Writer) *StreamMessageWriterChannel {
+	return &StreamMessageWriterChannel{stream: stream}
+}
+
+// WriteMessage writes a Message to the stream, serialized as a JSON object followed by a newline.
+func (s *StreamMessageWriterChannel) WriteMessage(message Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return writeLine(context.Background(), s.stream, append(data, '\n'), &s.failed)
+}
+
+// WriteMessageContext writes a Message like WriteMessage. The write is bounded by
+// the deadline of the context if the stream supports write deadlines.
+func (s *StreamMessageWriterChannel) WriteMessageContext(ctx context.Context, message Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return writeLine(ctx, s.stream, append(data, '\n'), &s.failed)
+}
+
+// Close is a no-op, the stream is owned by the caller.
+func (s *StreamMessageWriterChannel) Close() error {
+	return nil
+}
+
+// BlobStore represents a blob storage, such as S3, GCS or DBFS, that message chunks are uploaded to.
+type BlobStore interface {
+	// Put uploads data under the given key.
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// DirBlobStore is a BlobStore writing blobs as files into a local directory,
+// e.g. a DBFS mount.
+type DirBlobStore struct {
+	Dir string // Directory the blobs are written to.
+}
+
+// Put writes data to the file named key within the store directory.
+func (d *DirBlobStore) Put(_ context.Context, key string, data []byte) error {
+	path := filepath.Join(d.Dir, filepath.FromSlash(key))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
+
+// DefaultBlobUploadInterval is the default interval between uploads of message chunks.
+const DefaultBlobUploadInterval = 10 * time.Second
+
+// BlobMessageWriterChannelOptions defines configuration options for creating a new BlobMessageWriterChannel.
+type BlobMessageWriterChannelOptions struct {
+	Interval time.Duration // Interval between uploads of buffered messages. Defaults to DefaultBlobUploadInterval if not positive.
+}
+
+// BlobMessageWriterChannel implements the MessageChannel interface.
+// It buffers messages and periodically uploads them in chunks to a BlobStore,
+// using the keys "<key_prefix>/1.json", "<key_prefix>/2.json", and so on.
+type BlobMessageWriterChannel struct {
+	mu        sync.Mutex    // Protects the buffer and the chunk counter.
+	store     BlobStore     // Store the chunks are uploaded to.
+	keyPrefix string        // Prefix of the chunk keys.
+	buffer    bytes.Buffer  // Messages not yet uploaded.
+	counter   int           // Index of the last uploaded chunk.
+	stop      chan struct{} // Signals the upload loop to stop.
+	done      chan struct{} // Closed when the upload loop has stopped.
+	closeOnce sync.Once     // Ensures the upload loop is stopped only once.
+}
+
+// NewBlobMessageWriterChannel creates a new BlobMessageWriterChannel and starts
+// uploading buffered messages in the background.
+func NewBlobMessageWriterChannel(store BlobStore, keyPrefix string, optFns ...func(o *BlobMessageWriterChannelOptions)) *BlobMessageWriterChannel {
+	opts := BlobMessageWriterChannelOptions{
+		Interval: DefaultBlobUploadInterval,
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultBlobUploadInterval
+	}
+
+	b := &BlobMessageWriterChannel{
+		store:     store,
+		keyPrefix: keyPrefix,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	go b.uploadLoop(opts.Interval)
+
+	return b
+}
+
+// WriteMessage appends a Message to the buffer of the next chunk.
+func (b *BlobMessageWriterChannel) WriteMessage(message Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buffer.Write(append(data, '\n'))
+
+	return nil
+}
+
+// WriteMessageContext appends a Message to the buffer like WriteMessage, unless the context is done.
+func (b *BlobMessageWriterChannel) WriteMessageContext(ctx context.Context, message Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.WriteMessage(message)
+}
+
+// Flush uploads all buffered messages as a new chunk.
+func (b *BlobMessageWriterChannel) Flush() error {
+	return b.FlushContext(context.Background())
+}
+
+// FlushContext uploads all buffered messages as a new chunk, passing the context to the store.
+func (b *BlobMessageWriterChannel) FlushContext(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.buffer.Len() == 0 {
+		return nil
+	}
+
+	key := strconv.Itoa(b.counter+1) + ".json"
+	if b.keyPrefix != "" {
+		key = strings.TrimSuffix(b.keyPrefix, "/") + "/" + key
+	}
+
+	if err := b.store.Put(ctx, key, b.buffer.Bytes()); err != nil {
+		return err
+	}
+
+	b.counter++
+	b.buffer.Reset()
+
+	return nil
+}
+
+// Close stops the background uploads and uploads the remaining messages.
+func (b *BlobMessageWriterChannel) Close() error {
+	return b.CloseContext(context.Background())
+}
+
+// CloseContext stops the background uploads and uploads the remaining messages,
+// passing the context to the store.
+func (b *BlobMessageWriterChannel) CloseContext(ctx context.Context) error {
+	b.closeOnce.Do(func() {
+		close(b.stop)
+		<-b.done
+	})
+
+	return b.FlushContext(ctx)
+}
+
+// Capabilities returns the capabilities of the channel.
+func (b *BlobMessageWriterChannel) Capabilities() []string {
+	return []string{CapabilityChunking}
+}
+
+// uploadLoop periodically uploads the buffered messages until the channel is closed.
+func (b *BlobMessageWriterChannel) uploadLoop(interval time.Duration) {
+	defer close(b.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			// Failed uploads are retried with the next tick or on close.
+			_ = b.Flush()
+		}
+	}
+}
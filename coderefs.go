@@ -0,0 +1,213 @@
+package dagsterpipes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Metadata keys used for code references.
+const (
+	// MetadataKeyCodeReferences holds the code references as JSON. The Pipes protocol
+	// has no metadata type for Dagster's code references, so the reserved key
+	// "dagster/code_references" cannot be used and the references are shown as
+	// regular JSON metadata instead of in the code references view of the UI.
+	MetadataKeyCodeReferences = "code_references"
+
+	// MetadataKeyCodeReferenceURL holds a clickable link to the primary code reference.
+	MetadataKeyCodeReferenceURL = "code_reference_url"
+)
+
+// CodeReferencesOptions configures the automatic attachment of code references
+// to asset materializations.
+type CodeReferencesOptions struct {
+	Enabled     bool   // Whether code references are attached automatically.
+	GitURL      string // Repository URL, e.g. "https://github.com/org/repo". Local file references are used if empty.
+	GitRevision string // Commit or branch to link to. Defaults to the VCS revision of the build, or "HEAD".
+	LocalRoot   string // Local path of the repository root. Detected from the enclosing .git directory if empty.
+
+	// LinkFunc builds the URL of a source line. Defaults to GitHub-style links
+	// "<GitURL>/blob/<GitRevision>/<path>#L<line>", which GitLab understands as well.
+	LinkFunc func(gitURL, revision, path string, line int) string
+}
+
+// CodeReference represents a reference to a source location, either as local file or as URL.
+type CodeReference struct {
+	FilePath   string `json:"file_path,omitempty"`   // Absolute path of the local source file.
+	LineNumber int    `json:"line_number,omitempty"` // Line number within the local source file.
+	URL        string `json:"url,omitempty"`         // URL of the source location.
+	Label      string `json:"label,omitempty"`       // Human-readable label, e.g. the function name.
+}
+
+// CodeReferencesMetadata creates a metadata value holding code references, using
+// the structure of Dagster's code references metadata. The Pipes protocol has no
+// dedicated type for code references, hence the value is sent as JSON, see
+// MetadataKeyCodeReferences.
+func CodeReferencesMetadata(references ...CodeReference) MetadataValue {
+	return JSONMetadata(map[string]any{"code_references": references})
+}
+
+// codeLocation identifies a source location.
+type codeLocation struct {
+	file     string // Absolute path of the source file.
+	line     int    // Line number within the source file.
+	function string // Fully qualified function name.
+}
+
+// RegisterAssetFunc registers the function producing an asset. Code references
+// of the asset point to the function instead of the caller of ReportAssetMaterialization.
+func (c *Context[T]) RegisterAssetFunc(assetKey string, fn any) error {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return fmt.Errorf("expected a function for asset %s, got %T", assetKey, fn)
+	}
+
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return fmt.Errorf("cannot resolve function for asset %s", assetKey)
+	}
+
+	file, line := f.FileLine(f.Entry())
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.assetFuncs[assetKey] = codeLocation{file: file, line: line, function: f.Name()}
+
+	return nil
+}
+
+// codeReferencesMetadata returns the code reference metadata for the asset,
+// using the registered asset function or the caller outside this package.
+func (c *Context[T]) codeReferencesMetadata(assetKey string) map[string]any {
+	c.mu.RLock()
+	location, ok := c.assetFuncs[assetKey]
+	c.mu.RUnlock()
+
+	if !ok {
+		location, ok = callerLocation()
+		if !ok {
+			return nil
+		}
+	}
+
+	opts := c.codeReferences
+	reference := CodeReference{Label: shortFunctionName(location.function)}
+	metadata := make(map[string]any, 2)
+
+	if link := opts.link(location); link != "" {
+		reference.URL = link
+		metadata[MetadataKeyCodeReferenceURL] = URLMetadata(link)
+	} else {
+		reference.FilePath = location.file
+		reference.LineNumber = location.line
+	}
+
+	metadata[MetadataKeyCodeReferences] = CodeReferencesMetadata(reference)
+
+	return metadata
+}
+
+// link returns the repository URL of the location, or "" if no Git URL is configured
+// or the location is outside the repository.
+func (o *CodeReferencesOptions) link(location codeLocation) string {
+	if o.GitURL == "" {
+		return ""
+	}
+
+	root := o.LocalRoot
+	if root == "" {
+		root = findRepositoryRoot(filepath.Dir(location.file))
+	}
+
+	if root == "" {
+		return ""
+	}
+
+	rel, err := filepath.Rel(root, location.file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+
+	revision := o.GitRevision
+	if revision == "" {
+		revision = buildRevision()
+	}
+
+	linkFunc := o.LinkFunc
+	if linkFunc == nil {
+		linkFunc = gitHubLink
+	}
+
+	return linkFunc(strings.TrimSuffix(o.GitURL, "/"), revision, filepath.ToSlash(rel), location.line)
+}
+
+// gitHubLink builds a GitHub-style link to a source line.
+func gitHubLink(gitURL, revision, path string, line int) string {
+	return fmt.Sprintf("%s/blob/%s/%s#L%d", gitURL, revision, path, line)
+}
+
+// callerLocation returns the first caller outside of this package.
+func callerLocation() (codeLocation, bool) {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !isInternalFrame(frame) {
+			return codeLocation{file: frame.File, line: frame.Line, function: frame.Function}, true
+		}
+
+		if !more {
+			return codeLocation{}, false
+		}
+	}
+}
+
+// isInternalFrame reports whether the frame belongs to this package, excluding its tests.
+func isInternalFrame(frame runtime.Frame) bool {
+	return strings.HasPrefix(frame.Function, ModulePath+".") && !strings.HasSuffix(frame.File, "_test.go")
+}
+
+// findRepositoryRoot returns the closest parent directory containing a .git entry, or "".
+func findRepositoryRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+
+		dir = parent
+	}
+}
+
+// buildRevision returns the VCS revision recorded in the build info, or "HEAD".
+func buildRevision() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" && setting.Value != "" {
+				return setting.Value
+			}
+		}
+	}
+
+	return "HEAD"
+}
+
+// shortFunctionName strips the package path from a fully qualified function name.
+func shortFunctionName(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	return name
+}
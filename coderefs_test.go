@@ -0,0 +1,91 @@
+package dagsterpipes
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func materializeTestAsset() {}
+
+// sentMaterializationMetadata returns the metadata of the recorded materialization.
+func sentMaterializationMetadata(t *testing.T, channel *testMessageChannel) map[string]any {
+	t.Helper()
+
+	messages := channel.Messages(MethodReportAssetMaterialization)
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 materialization, got %d", len(messages))
+	}
+
+	params, _ := messages[0]["params"].(map[string]any)
+	metadata, _ := params["metadata"].(map[string]any)
+
+	return metadata
+}
+
+func TestCodeReferences(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+	root := filepath.Dir(file)
+
+	t.Run("Caller", func(t *testing.T) {
+		pc, channel := newTestContext(t, []string{"asset"}, func(o *Options[map[string]any]) {
+			o.CodeReferences = CodeReferencesOptions{Enabled: true}
+		})
+
+		materialization := &AssetMaterialization{Metadata: map[string]any{"foo": "bar"}}
+		if err := pc.ReportAssetMaterialization(materialization); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(materialization.Metadata) != 1 {
+			t.Fatalf("Expected caller's metadata to be left untouched, got %v", materialization.Metadata)
+		}
+
+		metadata := sentMaterializationMetadata(t, channel)
+
+		value, _ := metadata[MetadataKeyCodeReferences].(map[string]any)
+		if value["type"] != MetadataTypeJSON {
+			t.Fatalf("Expected code references, got %v", metadata)
+		}
+
+		rawValue, _ := value["raw_value"].(map[string]any)
+		references, _ := rawValue["code_references"].([]any)
+		if len(references) != 1 {
+			t.Fatalf("Expected 1 code reference, got %v", references)
+		}
+
+		if reference, _ := references[0].(map[string]any); reference["file_path"] != file || reference["line_number"] == nil {
+			t.Fatalf("Expected reference to this test file, got %+v", references)
+		}
+	})
+
+	t.Run("RegisteredFunc", func(t *testing.T) {
+		pc, channel := newTestContext(t, []string{"asset"}, func(o *Options[map[string]any]) {
+			o.CodeReferences = CodeReferencesOptions{
+				Enabled:     true,
+				GitURL:      "https://github.com/org/repo/",
+				GitRevision: "abc123",
+				LocalRoot:   root,
+			}
+		})
+
+		if err := pc.RegisterAssetFunc("asset", materializeTestAsset); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		materialization := &AssetMaterialization{}
+		if err := pc.ReportAssetMaterialization(materialization); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		link, _ := sentMaterializationMetadata(t, channel)[MetadataKeyCodeReferenceURL].(map[string]any)
+		if s, _ := link["raw_value"].(string); !strings.HasPrefix(s, "https://github.com/org/repo/blob/abc123/coderefs_test.go#L") {
+			t.Fatalf("Unexpected link: %v", link)
+		}
+
+		if err := pc.RegisterAssetFunc("asset", "not a func"); err == nil {
+			t.Fatal("Expected error for non-function")
+		}
+	})
+}
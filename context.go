@@ -2,30 +2,70 @@ package dagsterpipes
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"slices"
 	"sync"
+	"testing"
+	"time"
 )
 
 // Options defines configuration options for creating a new Context.
 type Options[T any] struct {
-	ParamsLoader  ParamsLoader[T]  // Loader for context parameters.
-	ContextLoader ContextLoader[T] // Loader for the execution context.
-	MessageWriter MessageWriter    // Writer for communication messages.
-	Logger        *slog.Logger     // Logger instance for logging messages.
+	ParamsLoader          ParamsLoader[T]       // Loader for context parameters.
+	ContextLoader         ContextLoader[T]      // Loader for the execution context.
+	MessageWriter         MessageWriter         // Writer for communication messages.
+	Logger                *slog.Logger          // Logger instance for logging messages.
+	Redactor              *Redactor             // Optional redactor scrubbing secrets from outgoing messages.
+	OpenedExtras          map[string]any        // Additional entries for the extras of the "opened" message.
+	CodeReferences        CodeReferencesOptions // Automatic code references for asset materializations.
+	Strict                bool                  // Validates outgoing messages against the protocol schema. Defaults to true in tests.
+	LevelMapper           LevelMapper           // Maps slog levels to Dagster log levels. Defaults to DefaultLevelMapper.
+	Exceptions            ExceptionOptions      // Configuration of reported exceptions, e.g. the stack trace depth.
+	PanicMode             PanicMode             // How a Session continues after recovering a panic. Defaults to PanicModeRepanic.
+	GracePeriod           time.Duration         // Time a Session run has to return after a signal. Defaults to DefaultGracePeriod.
+	DisableSignalHandling bool                  // Disables the trapping of SIGINT and SIGTERM by Session runs.
+	Progress              ProgressOptions       // Configuration of progress reporting and heartbeats.
+	Telemetry             TelemetryOptions      // Collection of resource usage telemetry.
+}
+
+// assetCheckKey identifies an asset check of an asset.
+type assetCheckKey struct {
+	assetKey  string // The key of the checked asset.
+	checkName string // The name of the check.
 }
 
 // Context represents a Dagster Pipes execution context.
 type Context[T any] struct {
-	data             *ContextData[T] // Contextual data for the process.
-	messageChannel   MessageChannel  // Channel to communicate messages.
-	materializedKeys map[string]any  // Tracks materialized assets to prevent duplicates.
-	exception        *Exception      // Holds the exception if one is reported.
-	closed           bool            // Indicates whether the context has been closed.
-	logger           *slog.Logger    // Logger instance for logging messages.
-	mu               sync.RWMutex    // Mutex to protect shared state
+	data             *ContextData[T]         // Contextual data for the process.
+	messageChannel   MessageChannel          // Channel to communicate messages.
+	materializedKeys map[string]any          // Tracks materialized assets to prevent duplicates.
+	reportedChecks   map[assetCheckKey]any   // Tracks reported asset checks to prevent duplicates.
+	exception        *Exception              // Holds the exception if one is reported.
+	exceptionNotes   []string                // Context notes reported with the exception.
+	state            contextState            // Lifecycle state of the context.
+	closing          chan struct{}           // Closed when the current close attempt has finished.
+	logger           *slog.Logger            // Logger instance for logging messages.
+	redactor         *Redactor               // Scrubs secrets from outgoing messages.
+	codeReferences   CodeReferencesOptions   // Configuration of automatic code references.
+	assetFuncs       map[string]codeLocation // Registered asset functions by asset key.
+	strict           bool                    // Validates outgoing messages against the protocol schema.
+	levelMapper      LevelMapper             // Maps slog levels to Dagster log levels.
+	exceptionOptions ExceptionOptions        // Configuration of reported exceptions.
+	panicMode        PanicMode               // How a Session continues after recovering a panic.
+	gracePeriod      time.Duration           // Time a Session run has to return after a signal.
+	signalHandling   bool                    // Whether Session runs trap SIGINT and SIGTERM.
+	progress         ProgressOptions         // Configuration of progress reporting and heartbeats.
+	openedAt         time.Time               // Time the context was opened.
+	lastProgress     Progress                // Most recently reported progress, repeated by heartbeats.
+	lastProgressAt   time.Time               // Time the last progress message was sent.
+	heartbeatCancel  context.CancelFunc      // Stops the heartbeats.
+	heartbeatDone    chan struct{}           // Closed when the heartbeats have stopped.
+	telemetryStart   *telemetrySample        // Resource usage when the context was opened, nil if telemetry is disabled.
+	mu               sync.RWMutex            // Mutex to protect shared state
+	writeMu          sync.Mutex              // Serializes writes to the message channel.
 }
 
 // NewContext initializes a new Context using the provided configuration functions.
@@ -36,12 +76,24 @@ func NewContext[T any](optFns ...func(o *Options[T])) (*Context[T], error) {
 		ContextLoader: &DefaultContextLoader[T]{},
 		MessageWriter: &DefaultMessageWriter{},
 		Logger:        slog.Default(),
+		Strict:        testing.Testing(),
+		LevelMapper:   DefaultLevelMapper,
+		Exceptions:    ExceptionOptions{StackDepth: DefaultStackDepth},
+		GracePeriod:   DefaultGracePeriod,
 	}
 
 	for _, fn := range optFns {
 		fn(&opts)
 	}
 
+	if opts.LevelMapper == nil {
+		opts.LevelMapper = DefaultLevelMapper
+	}
+
+	if opts.Progress.Interval <= 0 {
+		opts.Progress.Interval = DefaultProgressInterval
+	}
+
 	if !opts.ParamsLoader.IsDagsterPipesProcess() {
 		return nil, errors.New("not a Dagster Pipes process")
 	}
@@ -70,17 +122,65 @@ func NewContext[T any](optFns ...func(o *Options[T])) (*Context[T], error) {
 		data:             data,
 		messageChannel:   messageChannel,
 		materializedKeys: make(map[string]any),
-		closed:           false,
+		reportedChecks:   make(map[assetCheckKey]any),
+		state:            stateOpening,
 		logger:           opts.Logger,
+		redactor:         opts.Redactor,
+		codeReferences:   opts.CodeReferences,
+		assetFuncs:       make(map[string]codeLocation),
+		strict:           opts.Strict,
+		levelMapper:      opts.LevelMapper,
+		exceptionOptions: opts.Exceptions,
+		panicMode:        opts.PanicMode,
+		gracePeriod:      opts.GracePeriod,
+		signalHandling:   !opts.DisableSignalHandling,
+		progress:         opts.Progress,
+		openedAt:         time.Now(),
+	}
+
+	if opts.Telemetry.Enabled {
+		start := sampleTelemetry()
+		pc.telemetryStart = &start
+	}
+
+	extras := opts.MessageWriter.OpenedExtras()
+	if extras == nil {
+		extras = make(map[string]any, len(opts.OpenedExtras))
 	}
 
-	if err := pc.writeMessage(MethodOpened, &Opened[map[string]any]{Extras: opts.MessageWriter.OpenedExtras()}); err != nil {
+	for key, value := range opts.OpenedExtras {
+		extras[key] = value
+	}
+
+	if err := pc.writeMessageIn(context.Background(), stateOpening, MethodOpened, &Opened[map[string]any]{Extras: extras}); err != nil {
 		return nil, err
 	}
 
+	pc.mu.Lock()
+	pc.state = stateOpen
+	pc.mu.Unlock()
+
+	if opts.Progress.HeartbeatInterval > 0 {
+		pc.startHeartbeat()
+	}
+
 	return pc, nil
 }
 
+// pipesContextKey is the key of the pipes context in a context.Context.
+type pipesContextKey struct{}
+
+// WithContext returns a copy of ctx carrying the pipes context pc.
+func WithContext[T any](ctx context.Context, pc *Context[T]) context.Context {
+	return context.WithValue(ctx, pipesContextKey{}, pc)
+}
+
+// FromContext returns the pipes context carried by ctx, if any.
+func FromContext[T any](ctx context.Context) (*Context[T], bool) {
+	pc, ok := ctx.Value(pipesContextKey{}).(*Context[T])
+	return pc, ok
+}
+
 // RunID retrieves the run identifier from the context data.
 func (c *Context[T]) RunID() string {
 	return c.data.RunID
@@ -97,22 +197,38 @@ func (c *Context[T]) AssetKeys() []string {
 }
 
 // Close closes the context and sends a "closed" message.
-// Ensures the context cannot be used after it is closed.
+// Ensures the context cannot be used after it is closed: once Close started,
+// other messages are rejected with ErrContextClosed. Concurrent calls wait for
+// the first one, and only one "closed" message is sent.
 func (c *Context[T]) Close() error {
-	c.mu.RLock()
-	if c.closed {
-		c.mu.RUnlock()
-		return nil
+	return c.CloseContext(context.Background())
+}
+
+// CloseContext is like Close, but gives up writing the "closed" message and
+// closing the channel when ctx is canceled or its deadline is exceeded.
+func (c *Context[T]) CloseContext(ctx context.Context) error {
+	if err := c.stopHeartbeat(ctx); err != nil {
+		return err
 	}
-	c.mu.RUnlock()
 
-	if err := c.writeMessage(MethodClosed, &Closed{Exception: c.exception}); err != nil {
+	exception, ok, err := c.beginClose(ctx)
+	if !ok {
 		return err
 	}
 
-	c.mu.Lock()
-	c.closed = true
-	c.mu.Unlock()
+	// The summary is best effort, a failure must not prevent the "closed" message.
+	_ = c.reportTelemetrySummary(ctx)
+
+	if err := c.writeMessageIn(ctx, stateClosing, MethodClosed, &Closed{Exception: exception}); err != nil {
+		c.endClose(false)
+		return err
+	}
+
+	c.endClose(true)
+
+	if closer, ok := c.messageChannel.(ContextCloser); ok {
+		return closer.CloseContext(ctx)
+	}
 
 	if err := c.messageChannel.Close(); err != nil {
 		return err
@@ -126,47 +242,174 @@ func (c *Context[T]) IsClosed() bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	return c.closed
+	return c.state == stateClosed
+}
+
+// ReportAssetMaterializationOptions defines options for reporting an asset materialization.
+type ReportAssetMaterializationOptions struct {
+	DataVersionPaths     []string      // Output files or directories to compute the data version from, if it is not set.
+	DataVersionAlgorithm HashAlgorithm // Hash algorithm for the computed data version. Defaults to HashSHA256.
 }
 
 // ReportAssetMaterialization reports an asset materialization event.
 // Ensures duplicate materializations for the same asset key are prevented.
-func (c *Context[T]) ReportAssetMaterialization(materialization *AssetMaterialization) error {
-	assetKey := materialization.AssetKey
+func (c *Context[T]) ReportAssetMaterialization(materialization *AssetMaterialization, optFns ...func(o *ReportAssetMaterializationOptions)) error {
+	return c.ReportAssetMaterializationContext(context.Background(), materialization, optFns...)
+}
 
-	c.mu.RLock()
-	if _, exists := c.materializedKeys[assetKey]; exists {
-		c.mu.RUnlock()
-		return fmt.Errorf("asset with key %s has already been materialized", assetKey)
+// ReportAssetMaterializationContext is like ReportAssetMaterialization, but
+// gives up writing the message when ctx is canceled or its deadline is exceeded.
+func (c *Context[T]) ReportAssetMaterializationContext(ctx context.Context, materialization *AssetMaterialization, optFns ...func(o *ReportAssetMaterializationOptions)) error {
+	opts := ReportAssetMaterializationOptions{
+		DataVersionAlgorithm: HashSHA256,
 	}
-	c.mu.RUnlock()
 
-	assetKey, err := c.resolveOptionallyPassedAssetKey(assetKey)
-	if err != nil {
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	if err := materialization.Err(); err != nil {
 		return err
 	}
 
-	materialization.AssetKey = assetKey
+	assetKey, err := c.resolveOptionallyPassedAssetKey(materialization.AssetKey)
+	if err != nil {
+		return err
+	}
 
-	if err := c.writeMessage(MethodReportAssetMaterialization, materialization); err != nil {
+	if err := validateMetadata(materialization.Metadata); err != nil {
 		return err
 	}
 
+	// The key is reserved before any other work, so that concurrent reports of the
+	// same asset cannot both pass the check and rejected duplicates neither hash
+	// files nor modify the materialization. Failed reports release it again.
 	c.mu.Lock()
+	if _, exists := c.materializedKeys[assetKey]; exists {
+		c.mu.Unlock()
+		return fmt.Errorf("asset with key %s has already been materialized", assetKey)
+	}
 	c.materializedKeys[assetKey] = struct{}{}
 	c.mu.Unlock()
 
+	if err := c.reportAssetMaterialization(ctx, materialization, assetKey, opts); err != nil {
+		c.mu.Lock()
+		delete(c.materializedKeys, assetKey)
+		c.mu.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+// reportAssetMaterialization completes and sends a materialization of the reserved
+// asset key. The resolved asset key and computed data version are only set on the
+// caller's materialization once it has been sent.
+func (c *Context[T]) reportAssetMaterialization(ctx context.Context, materialization *AssetMaterialization, assetKey string, opts ReportAssetMaterializationOptions) error {
+	// Automatic metadata is merged into a copy, the caller's metadata is left untouched.
+	reported := *materialization
+	reported.AssetKey = assetKey
+
+	if reported.DataVersion == "" && len(opts.DataVersionPaths) > 0 {
+		dataVersion, err := DataVersionFromPaths(opts.DataVersionPaths, func(o *DataVersionOptions) {
+			o.Algorithm = opts.DataVersionAlgorithm
+		})
+		if err != nil {
+			return fmt.Errorf("failed to compute data version: %w", err)
+		}
+
+		reported.DataVersion = dataVersion
+	}
+
+	if c.codeReferences.Enabled {
+		reported.Metadata = mergeMetadata(c.codeReferencesMetadata(assetKey), reported.Metadata)
+	}
+
+	if c.telemetryStart != nil {
+		reported.Metadata = mergeMetadata(c.telemetryMetadata(), reported.Metadata)
+	}
+
+	if err := c.writeMessage(ctx, MethodReportAssetMaterialization, &reported); err != nil {
+		return err
+	}
+
+	materialization.AssetKey = assetKey
+	materialization.DataVersion = reported.DataVersion
+
 	return nil
 }
 
 // ReportAssetCheck sends a report for an asset check event.
+// The asset key is resolved like for materializations, the severity defaults to
+// AssetCheckSeverityError, and each check can only be reported once per asset.
 func (c *Context[T]) ReportAssetCheck(check *AssetCheck) error {
-	return c.writeMessage(MethodReportAssetCheck, check)
+	return c.ReportAssetCheckContext(context.Background(), check)
+}
+
+// ReportAssetCheckContext is like ReportAssetCheck, but gives up writing the
+// message when ctx is canceled or its deadline is exceeded.
+func (c *Context[T]) ReportAssetCheckContext(ctx context.Context, check *AssetCheck) error {
+	if check.CheckName == "" {
+		return errors.New("asset check requires a check name")
+	}
+
+	assetKey, err := c.resolveOptionallyPassedAssetKey(check.AssetKey)
+	if err != nil {
+		return err
+	}
+
+	severity := check.Serverity
+	switch severity {
+	case "":
+		severity = AssetCheckSeverityError
+	case AssetCheckSeverityWarn, AssetCheckSeverityError:
+	default:
+		return fmt.Errorf("invalid asset check severity %s, expected %s or %s", severity, AssetCheckSeverityWarn, AssetCheckSeverityError)
+	}
+
+	if err := validateMetadata(check.Metadata); err != nil {
+		return err
+	}
+
+	key := assetCheckKey{assetKey: assetKey, checkName: check.CheckName}
+
+	c.mu.Lock()
+	if _, exists := c.reportedChecks[key]; exists {
+		c.mu.Unlock()
+		return fmt.Errorf("asset check %s for asset %s has already been reported", check.CheckName, assetKey)
+	}
+	c.reportedChecks[key] = struct{}{}
+	c.mu.Unlock()
+
+	// The resolved asset key and severity are only set on the caller's check once it has been sent.
+	reported := *check
+	reported.AssetKey = assetKey
+	reported.Serverity = severity
+
+	if err := c.writeMessage(ctx, MethodReportAssetCheck, &reported); err != nil {
+		c.mu.Lock()
+		delete(c.reportedChecks, key)
+		c.mu.Unlock()
+
+		return err
+	}
+
+	check.AssetKey = assetKey
+	check.Serverity = severity
+
+	return nil
 }
 
 // ReportCustomMessage sends a custom message through the context.
 func (c *Context[T]) ReportCustomMessage(msg *CustomMessage) error {
-	return c.writeMessage(MethodReportCustomMessage, msg)
+	return c.ReportCustomMessageContext(context.Background(), msg)
+}
+
+// ReportCustomMessageContext is like ReportCustomMessage, but gives up writing
+// the message when ctx is canceled or its deadline is exceeded.
+func (c *Context[T]) ReportCustomMessageContext(ctx context.Context, msg *CustomMessage) error {
+	return c.writeMessage(ctx, MethodReportCustomMessage, msg)
 }
 
 // ReportException records an exception in the context for later reporting.
@@ -174,45 +417,110 @@ func (c *Context[T]) ReportException(err error) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.exception = NewException(err, true)
+	c.exception = NewException(err, true, func(o *ExceptionOptions) {
+		*o = c.exceptionOptions
+	})
 
 	return nil
 }
 
+// setException records an exception for later reporting.
+func (c *Context[T]) setException(exception *Exception) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.exception = exception
+}
+
+// AddExceptionContext records a context note, e.g. a breadcrumb of the current
+// processing step. The notes are appended to the message of the exception sent
+// on close, if one was reported.
+func (c *Context[T]) AddExceptionContext(note string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.exceptionNotes = append(c.exceptionNotes, note)
+}
+
 // LogDebug logs a debug-level message using the context's logger.
 // Also sends the log message to the message channel.
 func (c *Context[T]) LogDebug(message string) error {
-	return c.log(slog.LevelDebug, message)
+	return c.log(context.Background(), slog.LevelDebug, message)
+}
+
+// LogDebugContext is like LogDebug, but passes ctx to the logger and honors its cancellation.
+func (c *Context[T]) LogDebugContext(ctx context.Context, message string) error {
+	return c.log(ctx, slog.LevelDebug, message)
 }
 
 // LogInfo logs an informational message using the context's logger.
 // Also sends the log message to the message channel.
 func (c *Context[T]) LogInfo(message string) error {
-	return c.log(slog.LevelInfo, message)
+	return c.log(context.Background(), slog.LevelInfo, message)
+}
+
+// LogInfoContext is like LogInfo, but passes ctx to the logger and honors its cancellation.
+func (c *Context[T]) LogInfoContext(ctx context.Context, message string) error {
+	return c.log(ctx, slog.LevelInfo, message)
 }
 
 // LogWarn logs a warning-level message using the context's logger.
 // Also sends the log message to the message channel.
 func (c *Context[T]) LogWarn(message string) error {
-	return c.log(slog.LevelWarn, message)
+	return c.log(context.Background(), slog.LevelWarn, message)
+}
+
+// LogWarnContext is like LogWarn, but passes ctx to the logger and honors its cancellation.
+func (c *Context[T]) LogWarnContext(ctx context.Context, message string) error {
+	return c.log(ctx, slog.LevelWarn, message)
 }
 
 // LogError logs an error-level message using the context's logger.
 // Also sends the log message to the message channel.
 func (c *Context[T]) LogError(message string) error {
-	return c.log(slog.LevelError, message)
+	return c.log(context.Background(), slog.LevelError, message)
+}
+
+// LogErrorContext is like LogError, but passes ctx to the logger and honors its cancellation.
+func (c *Context[T]) LogErrorContext(ctx context.Context, message string) error {
+	return c.log(ctx, slog.LevelError, message)
+}
+
+// LogCritical logs a critical message using the context's logger.
+// Also sends the log message to the message channel.
+func (c *Context[T]) LogCritical(message string) error {
+	return c.log(context.Background(), LevelCritical, message)
+}
+
+// LogCriticalContext is like LogCritical, but passes ctx to the logger and honors its cancellation.
+func (c *Context[T]) LogCriticalContext(ctx context.Context, message string) error {
+	return c.log(ctx, LevelCritical, message)
+}
+
+// Log logs a message at an arbitrary slog level using the context's logger.
+// The level is mapped to a Dagster log level before it is sent to the message channel.
+func (c *Context[T]) Log(level slog.Level, message string) error {
+	return c.log(context.Background(), level, message)
+}
+
+// LogContext is like Log, but passes ctx to the logger and honors its cancellation.
+func (c *Context[T]) LogContext(ctx context.Context, level slog.Level, message string) error {
+	return c.log(ctx, level, message)
 }
 
 // log sends a log message at the specified level using the context's logger.
 // Writes the same message to the message channel for external processing.
-func (c *Context[T]) log(level slog.Level, message string) error {
-	c.logger.Log(context.Background(), level, message)
+func (c *Context[T]) log(ctx context.Context, level slog.Level, message string) error {
+	message = c.redactor.Redact(message)
 
-	if err := c.writeMessage(MethodLog, &Log{Message: message, Level: level.String()}); err != nil {
-		return err
-	}
+	c.logger.Log(withPipesLog(ctx), level, message)
 
-	return nil
+	return c.sendLog(ctx, level, message)
+}
+
+// sendLog sends a log message to the message channel without logging it locally.
+func (c *Context[T]) sendLog(ctx context.Context, level slog.Level, message string) error {
+	return c.writeMessage(ctx, MethodLog, &Log{Message: message, Level: string(c.levelMapper(level))})
 }
 
 // resolveOptionallyPassedAssetKey resolves the provided asset key based on context data.
@@ -237,18 +545,83 @@ func (c *Context[T]) resolveOptionallyPassedAssetKey(assetKey string) (string, e
 	return c.data.AssetKeys[0], nil
 }
 
-// writeMessage sends a message to the message channel.
-// Ensures the context is not closed before sending the message.
-func (c *Context[T]) writeMessage(method Method, params any) error {
-	if c.IsClosed() {
-		return errors.New("cannot send message after pipes context is closed")
+// mergeMetadata returns a new metadata map containing the entries of all maps.
+// Entries of later maps take precedence.
+func mergeMetadata(maps ...map[string]any) map[string]any {
+	merged := make(map[string]any)
+
+	for _, m := range maps {
+		for key, value := range m {
+			merged[key] = value
+		}
+	}
+
+	return merged
+}
+
+// writeMessage sends a message to the message channel of an open context.
+func (c *Context[T]) writeMessage(ctx context.Context, method Method, params any) error {
+	return c.writeMessageIn(ctx, stateOpen, method, params)
+}
+
+// writeMessageIn sends a message to the message channel if the context is in
+// the given state, and returns ErrContextClosed otherwise. Writes are serialized,
+// so that no message can be sent after the "closed" message. In strict mode, the
+// message must match the protocol schema. Channels implementing
+// ContextMessageChannel give up the write when ctx is done, for other channels
+// ctx is only checked before the write.
+func (c *Context[T]) writeMessageIn(ctx context.Context, state contextState, method Method, params any) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.mu.RLock()
+	current := c.state
+	c.mu.RUnlock()
+
+	if current != state {
+		return ErrContextClosed
 	}
 
 	msg := Message{
 		DagsterPipesVersion: ProtocolVersion,
 		Method:              method,
-		Params:              params,
+		Params:              c.redactor.redactParams(params),
+	}
+
+	if c.strict {
+		if err := ValidateMessage(msg); err != nil {
+			return fmt.Errorf("invalid %s message: %w", method, err)
+		}
 	}
 
-	return c.messageChannel.WriteMessage(msg)
+	var err error
+
+	if ch, ok := c.messageChannel.(ContextMessageChannel); ok {
+		err = ch.WriteMessageContext(ctx, msg)
+	} else if err = ctx.Err(); err == nil {
+		err = c.messageChannel.WriteMessage(msg)
+	}
+
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() != nil:
+		return err
+	case isMarshalError(err):
+		// The message itself cannot be serialized, which is an error of the caller.
+		return fmt.Errorf("invalid %s message: %w", method, err)
+	default:
+		return &ProtocolError{Op: "write", Err: err}
+	}
+}
+
+// isMarshalError reports whether err is a JSON serialization failure of a message.
+func isMarshalError(err error) bool {
+	var (
+		valueErr     *json.UnsupportedValueError
+		typeErr      *json.UnsupportedTypeError
+		marshalerErr *json.MarshalerError
+	)
+
+	return errors.As(err, &valueErr) || errors.As(err, &typeErr) || errors.As(err, &marshalerErr)
 }
@@ -0,0 +1,307 @@
+package dagsterpipes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"sync"
+	"testing"
+)
+
+// testParamsLoader is a ParamsLoader returning inline context data.
+type testParamsLoader struct {
+	data *ContextData[map[string]any]
+}
+
+func (l *testParamsLoader) LoadContextParams() (*ContextParams[map[string]any], error) {
+	return &ContextParams[map[string]any]{Data: l.data}, nil
+}
+
+func (l *testParamsLoader) LoadMessagesParams() (*MessagesParams, error) {
+	return &MessagesParams{}, nil
+}
+
+func (l *testParamsLoader) IsDagsterPipesProcess() bool {
+	return true
+}
+
+// testMessageChannel is a MessageChannel recording the serialized messages.
+type testMessageChannel struct {
+	mu       sync.Mutex
+	messages []map[string]any
+	closed   bool
+	err      error // Returned by WriteMessage if set.
+}
+
+func (ch *testMessageChannel) WriteMessage(message Message) error {
+	ch.mu.Lock()
+	err := ch.err
+	ch.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	ch.messages = append(ch.messages, decoded)
+
+	return nil
+}
+
+func (ch *testMessageChannel) Close() error {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	ch.closed = true
+
+	return nil
+}
+
+// isClosed reports whether the channel has been closed.
+func (ch *testMessageChannel) isClosed() bool {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	return ch.closed
+}
+
+// Messages returns the recorded messages with the given method.
+func (ch *testMessageChannel) Messages(method Method) []map[string]any {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	var messages []map[string]any
+
+	for _, msg := range ch.messages {
+		if msg["method"] == string(method) {
+			messages = append(messages, msg)
+		}
+	}
+
+	return messages
+}
+
+// testMessageWriter is a MessageWriter handing out a testMessageChannel.
+type testMessageWriter struct {
+	channel *testMessageChannel
+}
+
+func (mw *testMessageWriter) Open(_ *MessagesParams) (MessageChannel, error) {
+	return mw.channel, nil
+}
+
+func (mw *testMessageWriter) OpenedExtras() map[string]any {
+	return map[string]any{}
+}
+
+// newTestContext creates a Context for the given asset keys backed by a recording channel.
+func newTestContext(t *testing.T, assetKeys []string, optFns ...func(o *Options[map[string]any])) (*Context[map[string]any], *testMessageChannel) {
+	t.Helper()
+
+	channel := &testMessageChannel{}
+
+	fns := append([]func(o *Options[map[string]any]){func(o *Options[map[string]any]) {
+		o.ParamsLoader = &testParamsLoader{data: &ContextData[map[string]any]{AssetKeys: assetKeys, RunID: "run"}}
+		o.MessageWriter = &testMessageWriter{channel: channel}
+		o.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}}, optFns...)
+
+	pc, err := NewContext(fns...)
+	if err != nil {
+		t.Fatalf("Failed to create context: %v", err)
+	}
+
+	return pc, channel
+}
+
+func TestContext(t *testing.T) {
+	t.Run("ReportAssetMaterialization", func(t *testing.T) {
+		pc, channel := newTestContext(t, []string{"asset"})
+
+		if err := pc.ReportAssetMaterialization(&AssetMaterialization{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if err := pc.ReportAssetMaterialization(&AssetMaterialization{AssetKey: "asset"}); err == nil {
+			t.Fatal("Expected error for duplicate materialization")
+		}
+
+		if err := pc.ReportAssetMaterialization(&AssetMaterialization{}); err == nil {
+			t.Fatal("Expected error for duplicate materialization with resolved key")
+		}
+
+		messages := channel.Messages(MethodReportAssetMaterialization)
+		if len(messages) != 1 {
+			t.Fatalf("Expected 1 materialization, got %d", len(messages))
+		}
+
+		params, _ := messages[0]["params"].(map[string]any)
+		if params["asset_key"] != "asset" {
+			t.Fatalf("Expected asset key 'asset', got %v", params["asset_key"])
+		}
+	})
+
+	t.Run("ReportAssetCheck", func(t *testing.T) {
+		pc, channel := newTestContext(t, []string{"asset"})
+
+		check := &AssetCheck{CheckName: "check", Passed: true}
+		if err := pc.ReportAssetCheck(check); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if check.AssetKey != "asset" || check.Serverity != AssetCheckSeverityError {
+			t.Fatalf("Expected resolved key and default severity to be set, got %+v", check)
+		}
+
+		if err := pc.ReportAssetCheck(&AssetCheck{AssetKey: "asset", CheckName: "check"}); err == nil {
+			t.Fatal("Expected error for duplicate check")
+		}
+
+		duplicate := &AssetCheck{CheckName: "check"}
+		if err := pc.ReportAssetCheck(duplicate); err == nil {
+			t.Fatal("Expected error for duplicate check with resolved key")
+		}
+
+		if duplicate.AssetKey != "" || duplicate.Serverity != "" {
+			t.Fatalf("Expected rejected check to be left untouched, got %+v", duplicate)
+		}
+
+		if err := pc.ReportAssetCheck(&AssetCheck{AssetKey: "other", CheckName: "check"}); err == nil {
+			t.Fatal("Expected error for unknown asset key")
+		}
+
+		if err := pc.ReportAssetCheck(&AssetCheck{CheckName: "severity", Serverity: "FATAL"}); err == nil {
+			t.Fatal("Expected error for invalid severity")
+		}
+
+		if err := pc.ReportAssetCheck(&AssetCheck{}); err == nil {
+			t.Fatal("Expected error for missing check name")
+		}
+
+		messages := channel.Messages(MethodReportAssetCheck)
+		if len(messages) != 1 {
+			t.Fatalf("Expected 1 check, got %d", len(messages))
+		}
+
+		params, _ := messages[0]["params"].(map[string]any)
+		if params["asset_key"] != "asset" || params["severity"] != "ERROR" {
+			t.Fatalf("Expected resolved key and default severity, got %v", params)
+		}
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		pc, channel := newTestContext(t, []string{"asset"})
+
+		if err := pc.Close(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if err := pc.Close(); err != nil {
+			t.Fatalf("Unexpected error on second close: %v", err)
+		}
+
+		if !channel.closed {
+			t.Fatal("Expected channel to be closed")
+		}
+
+		if len(channel.Messages(MethodClosed)) != 1 {
+			t.Fatal("Expected exactly one closed message")
+		}
+
+		if err := pc.LogInfo("late"); err == nil {
+			t.Fatal("Expected error when logging after close")
+		}
+	})
+
+	t.Run("ContextAware", func(t *testing.T) {
+		session, channel := newTestSession(t)
+
+		if err := session.RunContext(context.Background(), func(ctx context.Context, _ *Context[map[string]any]) error {
+			pc, ok := FromContext[map[string]any](ctx)
+			if !ok || pc != session.Context() {
+				t.Fatal("Expected pipes context in context")
+			}
+
+			if err := pc.LogInfoContext(ctx, "running"); err != nil {
+				return err
+			}
+
+			canceled, cancel := context.WithCancel(ctx)
+			cancel()
+
+			var protocolErr *ProtocolError
+
+			err := pc.ReportAssetMaterializationContext(canceled, &AssetMaterialization{})
+			if !errors.Is(err, context.Canceled) || errors.As(err, &protocolErr) {
+				t.Fatalf("Expected canceled error, got %v", err)
+			}
+
+			return pc.ReportAssetMaterializationContext(ctx, &AssetMaterialization{})
+		}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(channel.Messages(MethodLog)) != 1 || len(channel.Messages(MethodReportAssetMaterialization)) != 1 {
+			t.Fatalf("Unexpected messages: %v", channel.messages)
+		}
+
+		if _, ok := FromContext[map[string]any](context.Background()); ok {
+			t.Fatal("Expected no pipes context")
+		}
+	})
+
+	t.Run("UnserializableMessage", func(t *testing.T) {
+		pc, channel := newTestContext(t, []string{"asset"}, func(o *Options[map[string]any]) {
+			o.Strict = false
+		})
+
+		var protocolErr *ProtocolError
+
+		err := pc.ReportCustomMessage(&CustomMessage{Payload: map[string]any{"x": math.NaN()}})
+		if err == nil || errors.As(err, &protocolErr) || ExitCode(err) != ExitCodeError {
+			t.Fatalf("Expected serialization error, got %v", err)
+		}
+
+		if len(channel.Messages(MethodReportCustomMessage)) != 0 {
+			t.Fatal("Unexpected custom message")
+		}
+	})
+
+	t.Run("AddExceptionContext", func(t *testing.T) {
+		pc, channel := newTestContext(t, []string{"asset"})
+
+		pc.AddExceptionContext("step: extract")
+
+		if err := pc.ReportException(errors.New("boom")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		pc.AddExceptionContext("rows: 42")
+
+		if err := pc.Close(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		params, _ := channel.Messages(MethodClosed)[0]["params"].(map[string]any)
+		exception, _ := params["exception"].(map[string]any)
+
+		if exception["message"] != "boom\nstep: extract\nrows: 42" {
+			t.Fatalf("Expected notes in exception message, got %v", exception["message"])
+		}
+	})
+}
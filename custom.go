@@ -0,0 +1,160 @@
+package dagsterpipes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// CustomEnvelope wraps the payload of a typed custom message with its type tag and schema version.
+type CustomEnvelope[P any] struct {
+	Type    string `json:"type,omitempty"`    // The type tag identifying the payload type.
+	Version int    `json:"version,omitempty"` // The schema version of the payload.
+	Payload P      `json:"payload"`           // The payload.
+}
+
+// PayloadTyper can be implemented by payloads to provide their default type tag.
+type PayloadTyper interface {
+	PayloadType() string
+}
+
+// ReportCustomOptions defines options for reporting a typed custom message.
+type ReportCustomOptions struct {
+	Type    string // The type tag. Defaults to PayloadType() if the payload implements PayloadTyper.
+	Version int    // The schema version of the payload.
+}
+
+// ReportCustom sends a typed custom message, wrapping the payload in a CustomEnvelope.
+func ReportCustom[P any, T any](c *Context[T], payload P, optFns ...func(o *ReportCustomOptions)) error {
+	return ReportCustomContext(context.Background(), c, payload, optFns...)
+}
+
+// ReportCustomContext is like ReportCustom, but gives up writing the message
+// when ctx is canceled or its deadline is exceeded.
+func ReportCustomContext[P any, T any](ctx context.Context, c *Context[T], payload P, optFns ...func(o *ReportCustomOptions)) error {
+	opts := ReportCustomOptions{}
+
+	if typer, ok := any(payload).(PayloadTyper); ok {
+		opts.Type = typer.PayloadType()
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	return c.ReportCustomMessageContext(ctx, &CustomMessage{Payload: CustomEnvelope[P]{
+		Type:    opts.Type,
+		Version: opts.Version,
+		Payload: payload,
+	}})
+}
+
+// DecodedPayload represents a custom message payload decoded by a PayloadRegistry.
+type DecodedPayload struct {
+	Type    string // The type tag of the payload.
+	Version int    // The schema version of the payload.
+	Payload any    // The decoded payload, of the registered Go type.
+}
+
+// payloadKey identifies a registered payload type.
+type payloadKey struct {
+	typeTag string // The type tag.
+	version int    // The schema version.
+}
+
+// PayloadRegistry maps type tags and schema versions of custom message payloads
+// to Go types, so that consumers can decode payloads into typed values.
+type PayloadRegistry struct {
+	mu       sync.RWMutex                                      // Protects the decoders.
+	decoders map[payloadKey]func(json.RawMessage) (any, error) // Decoders by type tag and version.
+}
+
+// NewPayloadRegistry creates a new, empty PayloadRegistry.
+func NewPayloadRegistry() *PayloadRegistry {
+	return &PayloadRegistry{decoders: make(map[payloadKey]func(json.RawMessage) (any, error))}
+}
+
+// DefaultPayloadRegistry is the registry used by RegisterDefaultPayload.
+var DefaultPayloadRegistry = NewPayloadRegistry()
+
+// RegisterPayload registers the Go type P for the type tag and schema version.
+func RegisterPayload[P any](r *PayloadRegistry, typeTag string, version int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.decoders[payloadKey{typeTag: typeTag, version: version}] = func(data json.RawMessage) (any, error) {
+		var payload P
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return nil, err
+		}
+
+		return payload, nil
+	}
+}
+
+// RegisterDefaultPayload registers the Go type P in the DefaultPayloadRegistry.
+func RegisterDefaultPayload[P any](typeTag string, version int) {
+	RegisterPayload[P](DefaultPayloadRegistry, typeTag, version)
+}
+
+// Decode decodes a JSON-encoded CustomEnvelope into the registered Go type.
+func (r *PayloadRegistry) Decode(data []byte) (*DecodedPayload, error) {
+	var envelope CustomEnvelope[json.RawMessage]
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	decode, ok := r.decoders[payloadKey{typeTag: envelope.Type, version: envelope.Version}]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no payload registered for type %q version %d", envelope.Type, envelope.Version)
+	}
+
+	payload, err := decode(envelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode payload of type %q version %d: %w", envelope.Type, envelope.Version, err)
+	}
+
+	return &DecodedPayload{Type: envelope.Type, Version: envelope.Version, Payload: payload}, nil
+}
+
+// DecodeMessage decodes the payload of a JSON-encoded "report_custom_message" Message.
+func (r *PayloadRegistry) DecodeMessage(data []byte) (*DecodedPayload, error) {
+	var msg struct {
+		Method Method `json:"method"`
+		Params struct {
+			Payload json.RawMessage `json:"payload"`
+		} `json:"params"`
+	}
+
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, err
+	}
+
+	if msg.Method != MethodReportCustomMessage {
+		return nil, fmt.Errorf("expected a %s message, got %q", MethodReportCustomMessage, msg.Method)
+	}
+
+	return r.Decode(msg.Params.Payload)
+}
+
+// DecodePayload decodes a JSON-encoded CustomEnvelope using the registry and
+// returns the payload as P. Returns an error if the registered type is not P.
+func DecodePayload[P any](r *PayloadRegistry, data []byte) (P, error) {
+	var zero P
+
+	decoded, err := r.Decode(data)
+	if err != nil {
+		return zero, err
+	}
+
+	payload, ok := decoded.Payload.(P)
+	if !ok {
+		return zero, fmt.Errorf("payload of type %q is %T, not %T", decoded.Type, decoded.Payload, zero)
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,66 @@
+package dagsterpipes
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type testProgressPayload struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+func (testProgressPayload) PayloadType() string { return "progress" }
+
+func TestReportCustom(t *testing.T) {
+	pc, channel := newTestContext(t, []string{"asset"})
+
+	if err := ReportCustom(pc, testProgressPayload{Done: 1, Total: 2}, func(o *ReportCustomOptions) {
+		o.Version = 2
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	messages := channel.Messages(MethodReportCustomMessage)
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 custom message, got %d", len(messages))
+	}
+
+	data, err := json.Marshal(messages[0])
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	registry := NewPayloadRegistry()
+
+	if _, err := registry.DecodeMessage(data); err == nil {
+		t.Fatal("Expected error for unregistered payload")
+	}
+
+	RegisterPayload[testProgressPayload](registry, "progress", 2)
+
+	decoded, err := registry.DecodeMessage(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if decoded.Type != "progress" || decoded.Version != 2 {
+		t.Fatalf("Unexpected envelope: %+v", decoded)
+	}
+
+	payload, ok := decoded.Payload.(testProgressPayload)
+	if !ok || payload.Done != 1 || payload.Total != 2 {
+		t.Fatalf("Unexpected payload: %#v", decoded.Payload)
+	}
+
+	envelope := []byte(`{"type":"progress","version":2,"payload":{"done":3,"total":4}}`)
+
+	typed, err := DecodePayload[testProgressPayload](registry, envelope)
+	if err != nil || typed.Done != 3 {
+		t.Fatalf("Unexpected typed payload: %#v (%v)", typed, err)
+	}
+
+	if _, err := DecodePayload[string](registry, envelope); err == nil {
+		t.Fatal("Expected error for mismatching payload type")
+	}
+}
@@ -4,9 +4,39 @@
 // integration with Dagster's asset and pipeline infrastructure.
 package dagsterpipes
 
+import "runtime/debug"
+
 // ProtocolVersion defines the current version of the Dagster Pipes protocol.
 // This version is used to ensure compatibility between the client implementation
 // and the Dagster system.
 const (
 	ProtocolVersion = "0.1"
 )
+
+// ModulePath is the import path of this library.
+const ModulePath = "github.com/hupe1980/dagster-pipes-go"
+
+// Version returns the version of this library as recorded in the build info
+// of the running binary, or "(devel)" if it is unknown.
+func Version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(devel)"
+	}
+
+	if info.Main.Path == ModulePath && info.Main.Version != "" {
+		return info.Main.Version
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path == ModulePath {
+			if dep.Replace != nil && dep.Replace.Version != "" {
+				return dep.Replace.Version
+			}
+
+			return dep.Version
+		}
+	}
+
+	return "(devel)"
+}
@@ -0,0 +1,244 @@
+package dagsterpipes
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// HashAlgorithm represents the hash algorithm used to compute data versions.
+type HashAlgorithm string
+
+const (
+	// HashSHA256 computes data versions using SHA-256.
+	HashSHA256 HashAlgorithm = "sha256"
+
+	// HashSHA512 computes data versions using SHA-512.
+	HashSHA512 HashAlgorithm = "sha512"
+
+	// HashFNV128a computes data versions using the non-cryptographic FNV-1a 128-bit hash.
+	HashFNV128a HashAlgorithm = "fnv128a"
+)
+
+// newHash creates a new hash for the algorithm.
+func (a HashAlgorithm) newHash() (hash.Hash, error) {
+	switch a {
+	case HashSHA256, "":
+		return sha256.New(), nil
+	case HashSHA512:
+		return sha512.New(), nil
+	case HashFNV128a:
+		return fnv.New128a(), nil
+	default:
+		return nil, fmt.Errorf("unsupported hash algorithm %q", a)
+	}
+}
+
+// DataVersionOptions defines configuration options for computing data versions.
+type DataVersionOptions struct {
+	Algorithm HashAlgorithm // The hash algorithm. Defaults to HashSHA256.
+}
+
+// DataVersionFromReader computes a data version from the content of a reader.
+func DataVersionFromReader(r io.Reader, optFns ...func(o *DataVersionOptions)) (string, error) {
+	h, err := newDataVersionHash(optFns)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// DataVersionFromFile computes a data version from the content of a file.
+func DataVersionFromFile(path string, optFns ...func(o *DataVersionOptions)) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	return DataVersionFromReader(file, optFns...)
+}
+
+// DataVersionFromDir computes a data version from a directory tree. The version
+// covers the relative paths and contents of all regular files, independent of
+// the location of the directory. Symlinks to files are followed, symlinks to
+// directories within the tree are rejected.
+func DataVersionFromDir(root string, optFns ...func(o *DataVersionOptions)) (string, error) {
+	return DataVersionFromPaths([]string{root}, optFns...)
+}
+
+// DataVersionFromPaths computes a data version from files and directory trees.
+// Files are versioned like DataVersionFromFile and directories like
+// DataVersionFromDir, roots may be symlinks. A single path yields its own
+// version, multiple paths a version covering the versions of all paths in order.
+func DataVersionFromPaths(paths []string, optFns ...func(o *DataVersionOptions)) (string, error) {
+	versions := make([]string, len(paths))
+
+	for i, root := range paths {
+		version, err := dataVersionFromPath(root, optFns)
+		if err != nil {
+			return "", err
+		}
+
+		versions[i] = version
+	}
+
+	if len(versions) == 1 {
+		return versions[0], nil
+	}
+
+	h, err := newDataVersionHash(optFns)
+	if err != nil {
+		return "", err
+	}
+
+	for i, version := range versions {
+		fmt.Fprintf(h, "%d\x00%s\n", i, version)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// dataVersionFromPath computes the data version of a file or directory tree, following a symlinked root.
+func dataVersionFromPath(root string, optFns []func(o *DataVersionOptions)) (string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return "", err
+	}
+
+	if !info.IsDir() {
+		return DataVersionFromFile(root, optFns...)
+	}
+
+	// WalkDir does not descend into a symlinked root.
+	root, err = filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+
+	h, err := newDataVersionHash(optFns)
+	if err != nil {
+		return "", err
+	}
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.Type()&fs.ModeSymlink != 0 {
+			info, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() {
+				return fmt.Errorf("symlinked directory %s is not supported", path)
+			}
+
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+		} else if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		return hashFile(h, filepath.ToSlash(rel), path)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// DataVersionFromValues computes a data version from Go values using their
+// canonical JSON representation, in which object keys are sorted.
+func DataVersionFromValues(values []any, optFns ...func(o *DataVersionOptions)) (string, error) {
+	h, err := newDataVersionHash(optFns)
+	if err != nil {
+		return "", err
+	}
+
+	for _, value := range values {
+		data, err := canonicalJSON(value)
+		if err != nil {
+			return "", err
+		}
+
+		h.Write(data)
+		h.Write([]byte{'\n'})
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// newDataVersionHash creates the hash configured by the options.
+func newDataVersionHash(optFns []func(o *DataVersionOptions)) (hash.Hash, error) {
+	opts := DataVersionOptions{
+		Algorithm: HashSHA256,
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	return opts.Algorithm.newHash()
+}
+
+// hashFile writes the name, size and content of a file to the hash.
+func hashFile(h hash.Hash, name, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(h, "%s\x00%d\x00", name, info.Size())
+
+	_, err = io.Copy(h, file)
+
+	return err
+}
+
+// canonicalJSON serializes a value to JSON with sorted object keys.
+func canonicalJSON(value any) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var decoded any
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(decoded)
+}
@@ -0,0 +1,185 @@
+package dagsterpipes
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDataVersion(t *testing.T) {
+	t.Run("Reader", func(t *testing.T) {
+		version, err := DataVersionFromReader(strings.NewReader("hello"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if want := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"; version != want {
+			t.Fatalf("Expected %s, got %s", want, version)
+		}
+
+		fnv, err := DataVersionFromReader(strings.NewReader("hello"), func(o *DataVersionOptions) {
+			o.Algorithm = HashFNV128a
+		})
+		if err != nil || len(fnv) != 32 {
+			t.Fatalf("Expected 128-bit FNV version, got %q (%v)", fnv, err)
+		}
+
+		if _, err := DataVersionFromReader(strings.NewReader(""), func(o *DataVersionOptions) {
+			o.Algorithm = "md4"
+		}); err == nil {
+			t.Fatal("Expected error for unsupported algorithm")
+		}
+	})
+
+	t.Run("Dir", func(t *testing.T) {
+		write := func(dir, name, content string) {
+			path := filepath.Join(dir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		a, b := t.TempDir(), t.TempDir()
+		for _, dir := range []string{a, b} {
+			write(dir, "part-0.csv", "1,2")
+			write(dir, "nested/part-1.csv", "3,4")
+		}
+
+		versionA, err := DataVersionFromDir(a)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		versionB, err := DataVersionFromDir(b)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if versionA != versionB {
+			t.Fatal("Expected identical trees to have the same version")
+		}
+
+		write(b, "nested/part-1.csv", "3,5")
+
+		if versionC, _ := DataVersionFromDir(b); versionC == versionA {
+			t.Fatal("Expected changed content to change the version")
+		}
+	})
+
+	t.Run("Paths", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("Symlinks require privileges on Windows")
+		}
+
+		dir := t.TempDir()
+		file := filepath.Join(dir, "data.csv")
+
+		if err := os.WriteFile(file, []byte("1,2"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		fromFile, err := DataVersionFromFile(file)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if fromPaths, err := DataVersionFromPaths([]string{file}); err != nil || fromPaths != fromFile {
+			t.Fatalf("Expected single file to match DataVersionFromFile, got %s (%v)", fromPaths, err)
+		}
+
+		link := filepath.Join(t.TempDir(), "link.csv")
+		if err := os.Symlink(file, link); err != nil {
+			t.Fatal(err)
+		}
+
+		if fromLink, err := DataVersionFromPaths([]string{link}); err != nil || fromLink != fromFile {
+			t.Fatalf("Expected symlinked file to be followed, got %s (%v)", fromLink, err)
+		}
+
+		tree := t.TempDir()
+		if err := os.Symlink(file, filepath.Join(tree, "data.csv")); err != nil {
+			t.Fatal(err)
+		}
+
+		before, err := DataVersionFromDir(tree)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if err := os.WriteFile(file, []byte("3,4"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if after, _ := DataVersionFromDir(tree); after == before {
+			t.Fatal("Expected changed symlink target to change the version")
+		}
+
+		rootLink := filepath.Join(t.TempDir(), "tree")
+		if err := os.Symlink(tree, rootLink); err != nil {
+			t.Fatal(err)
+		}
+
+		fromTree, err := DataVersionFromDir(tree)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if fromRootLink, err := DataVersionFromDir(rootLink); err != nil || fromRootLink != fromTree {
+			t.Fatalf("Expected symlinked root to be followed, got %s (%v)", fromRootLink, err)
+		}
+
+		if err := os.Symlink(dir, filepath.Join(tree, "dir")); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := DataVersionFromDir(tree); err == nil {
+			t.Fatal("Expected error for symlinked directory")
+		}
+	})
+
+	t.Run("Values", func(t *testing.T) {
+		fromStruct, err := DataVersionFromValues([]any{struct {
+			B int `json:"b"`
+			A int `json:"a"`
+		}{B: 2, A: 1}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		fromMap, err := DataVersionFromValues([]any{map[string]int{"a": 1, "b": 2}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if fromStruct != fromMap {
+			t.Fatal("Expected canonical JSON to be independent of field order")
+		}
+	})
+
+	t.Run("ReportAssetMaterialization", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "output.csv")
+		if err := os.WriteFile(path, []byte("1,2"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		pc, _ := newTestContext(t, []string{"asset"})
+
+		materialization := &AssetMaterialization{}
+		if err := pc.ReportAssetMaterialization(materialization, func(o *ReportAssetMaterializationOptions) {
+			o.DataVersionPaths = []string{path}
+		}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		want, _ := DataVersionFromPaths([]string{path})
+		if materialization.DataVersion == "" || materialization.DataVersion != want {
+			t.Fatalf("Expected data version %s, got %q", want, materialization.DataVersion)
+		}
+	})
+}
@@ -2,59 +2,458 @@ package dagsterpipes
 
 import (
 	"fmt"
+	"reflect"
 	"runtime"
+	"slices"
+	"strings"
+)
+
+const (
+	// DefaultStackDepth is the default maximum number of frames of a stack trace.
+	DefaultStackDepth = 64
+
+	// DefaultExceptionDepth is the default maximum nesting depth of causes and contexts.
+	DefaultExceptionDepth = 32
 )
 
 // Exception represents a structured error with detailed context.
+//
+// Errors wrapping multiple errors, e.g. created by errors.Join, are represented
+// with the first error as Cause. The remaining errors are chained through Context,
+// each wrapped in an exception named after the joining error with a message like
+// "cause 2 of 3", which Dagster renders like an exception raised while handling another.
 type Exception struct {
 	Name    string     `json:"name"`
 	Message string     `json:"message"`
 	Cause   *Exception `json:"cause"`
 	Stack   []string   `json:"stack"`
-	Context []string   `json:"context"`
+	Context *Exception `json:"context"`
+}
+
+// ExceptionNamer is implemented by errors providing the name of their exception,
+// e.g. "ValidationError", instead of the Go type name.
+type ExceptionNamer interface {
+	ExceptionName() string
+}
+
+// ExceptionNoter is implemented by errors attaching context notes to their
+// exception. Notes are appended to the exception message as separate lines,
+// like the notes of Python exceptions.
+type ExceptionNoter interface {
+	ExceptionNotes() []string
+}
+
+// WithNotes annotates an error with context notes reported with its exception.
+// Returns nil if err is nil.
+func WithNotes(err error, notes ...string) error {
+	if err == nil {
+		return nil
+	}
+
+	return &notedError{err: err, notes: notes}
+}
+
+// notedError is an error annotated with context notes.
+type notedError struct {
+	err   error    // The annotated error.
+	notes []string // The context notes.
+}
+
+// Error returns the message of the annotated error.
+func (e *notedError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the annotated error.
+func (e *notedError) Unwrap() error {
+	return e.err
+}
+
+// ExceptionName returns the name of the annotated error.
+func (e *notedError) ExceptionName() string {
+	return getExceptionName(e.err)
+}
+
+// ExceptionNotes returns the context notes.
+func (e *notedError) ExceptionNotes() []string {
+	return e.notes
+}
+
+// ExceptionOptions defines configuration options for creating exceptions.
+type ExceptionOptions struct {
+	StackDepth           int  // Maximum number of frames of a stack trace. Defaults to DefaultStackDepth if not positive.
+	MaxDepth             int  // Maximum nesting depth of causes and contexts. Defaults to DefaultExceptionDepth if not positive.
+	IncludeRuntimeFrames bool // Whether frames of the Go runtime are kept.
+}
+
+// StackTracer is implemented by errors carrying the stack trace of their origin,
+// like the errors created by WithStack.
+//
+// Errors with a StackTrace method returning another slice of program counters,
+// such as errors.StackTrace of github.com/pkg/errors, or a slice of strings are
+// supported as well.
+type StackTracer interface {
+	StackTrace() []uintptr
 }
 
 // NewException creates a new Exception from a given error.
-// If includeStackTrace is true, it captures the stack trace.
-func NewException(err error, includeStackTrace bool) *Exception {
+// If includeStackTrace is true, it uses the stack trace carried by the error or,
+// if there is none, captures the current stack trace.
+func NewException(err error, includeStackTrace bool, optFns ...func(o *ExceptionOptions)) *Exception {
+	opts := ExceptionOptions{
+		StackDepth: DefaultStackDepth,
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	if opts.StackDepth <= 0 {
+		opts.StackDepth = DefaultStackDepth
+	}
+
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = DefaultExceptionDepth
+	}
+
 	if err == nil {
 		return nil
 	}
 
-	var stack []string
+	builder := &exceptionBuilder{
+		opts:              &opts,
+		includeStackTrace: includeStackTrace,
+		ancestors:         make(map[error]struct{}),
+	}
+
+	exception := builder.build(err, 0)
+
+	// The root exception uses the stack trace closest to the origin of the error,
+	// or the current stack trace if no error in the chain carries one.
 	if includeStackTrace {
-		stack = captureStackTrace()
+		exception.Stack = originStackTrace(err, &opts)
+		if exception.Stack == nil {
+			exception.Stack = captureStack(&opts)
+		}
 	}
 
-	return &Exception{
-		Name:    getTypeName(err),
+	return exception
+}
+
+// exceptionBuilder converts error trees into exceptions, guarding against cycles
+// and excessive depth.
+type exceptionBuilder struct {
+	opts              *ExceptionOptions  // Configuration of the exceptions.
+	includeStackTrace bool               // Whether stack traces carried by errors are included.
+	ancestors         map[error]struct{} // Errors on the path from the root, to detect cycles.
+}
+
+// build creates the exception of an error at the given depth, including its causes.
+func (b *exceptionBuilder) build(err error, depth int) *Exception {
+	if err == nil {
+		return nil
+	}
+
+	if depth >= b.opts.MaxDepth {
+		return &Exception{
+			Name:    getExceptionName(err),
+			Message: fmt.Sprintf("exception chain truncated at depth %d", b.opts.MaxDepth),
+			Stack:   []string{},
+		}
+	}
+
+	// Only pointers are tracked, as other errors may not be comparable and cannot form cycles.
+	if reflect.ValueOf(err).Kind() == reflect.Pointer {
+		if _, ok := b.ancestors[err]; ok {
+			return &Exception{
+				Name:    getExceptionName(err),
+				Message: "cyclic error chain",
+				Stack:   []string{},
+			}
+		}
+
+		b.ancestors[err] = struct{}{}
+		defer delete(b.ancestors, err)
+	}
+
+	stack := []string{}
+	if b.includeStackTrace {
+		if s := errorStackTrace(err, b.opts); s != nil {
+			stack = s
+		}
+	}
+
+	exception := &Exception{
+		Name:    getExceptionName(err),
 		Message: err.Error(),
-		Cause:   NewException(getCause(err), false),
 		Stack:   stack,
-		Context: []string{},
+	}
+
+	if noter, ok := err.(ExceptionNoter); ok {
+		exception = exception.withNotes(noter.ExceptionNotes())
+	}
+
+	causes := getCauses(err)
+	if len(causes) > 0 {
+		exception.Cause = b.build(causes[0], depth+1)
+	}
+
+	if len(causes) > 1 {
+		exception.Context = b.buildSiblings(err, causes, 1, depth+1)
+	}
+
+	return exception
+}
+
+// buildSiblings chains the causes of a joining error, starting at index i,
+// through exceptions named after the joining error.
+func (b *exceptionBuilder) buildSiblings(err error, causes []error, i int, depth int) *Exception {
+	if i >= len(causes) {
+		return nil
+	}
+
+	if depth >= b.opts.MaxDepth {
+		return &Exception{
+			Name:    getExceptionName(err),
+			Message: fmt.Sprintf("%d more causes truncated at depth %d", len(causes)-i, b.opts.MaxDepth),
+			Stack:   []string{},
+		}
+	}
+
+	return &Exception{
+		Name:    getExceptionName(err),
+		Message: fmt.Sprintf("cause %d of %d", i+1, len(causes)),
+		Cause:   b.build(causes[i], depth+1),
+		Stack:   []string{},
+		Context: b.buildSiblings(err, causes, i+1, depth+1),
 	}
 }
 
-// captureStackTrace captures the current stack trace as a slice of strings.
-func captureStackTrace() []string {
+// PanicExceptionName is the name of exceptions created from recovered panics.
+const PanicExceptionName = "panic"
+
+// NewPanicException creates a new Exception from a recovered panic value, with
+// the stack trace of the panicking goroutine. It must be called by the deferred
+// function recovering the panic. Panic values that are errors become the cause.
+func NewPanicException(value any, optFns ...func(o *ExceptionOptions)) *Exception {
+	opts := ExceptionOptions{
+		StackDepth: DefaultStackDepth,
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	if opts.StackDepth <= 0 {
+		opts.StackDepth = DefaultStackDepth
+	}
+
+	exception := &Exception{
+		Name:    PanicExceptionName,
+		Message: fmt.Sprint(value),
+		Stack:   captureStack(&opts),
+	}
+
+	if err, ok := value.(error); ok {
+		exception.Cause = NewException(err, false, func(o *ExceptionOptions) {
+			*o = opts
+		})
+	}
+
+	return exception
+}
+
+// WithStack annotates an error with the stack trace at the point WithStack is called.
+// The stack trace is used instead of the one of the reporting call when the error
+// is reported as exception. Returns nil if err is nil.
+func WithStack(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	pcs := make([]uintptr, DefaultStackDepth)
+	n := runtime.Callers(2, pcs)
+
+	return &stackError{err: err, pcs: pcs[:n]}
+}
+
+// stackError is an error annotated with a stack trace.
+type stackError struct {
+	err error     // The annotated error.
+	pcs []uintptr // Program counters of the stack trace.
+}
+
+// Error returns the message of the annotated error.
+func (e *stackError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the annotated error.
+func (e *stackError) Unwrap() error {
+	return e.err
+}
+
+// ExceptionName returns the name of the annotated error.
+func (e *stackError) ExceptionName() string {
+	return getExceptionName(e.err)
+}
+
+// StackTrace returns the program counters of the stack trace.
+func (e *stackError) StackTrace() []uintptr {
+	return e.pcs
+}
+
+// originStackTrace returns the stack trace of the innermost error in the chain
+// carrying one, which is closest to where the error originated, or nil. Like the
+// cause chain of the exception, it follows the first cause of joined errors up to
+// the maximum depth, which also bounds cyclic chains.
+func originStackTrace(err error, opts *ExceptionOptions) []string {
 	var stack []string
 
-	pcs := make([]uintptr, 10)
+	for depth := 0; err != nil && depth < opts.MaxDepth; depth++ {
+		if s := errorStackTrace(err, opts); s != nil {
+			stack = s
+		}
+
+		causes := getCauses(err)
+		if len(causes) == 0 {
+			break
+		}
+
+		err = causes[0]
+	}
+
+	return stack
+}
+
+// errorStackTrace returns the stack trace carried by the error itself, or nil.
+func errorStackTrace(err error, opts *ExceptionOptions) []string {
+	if tracer, ok := err.(StackTracer); ok {
+		return formatFrames(tracer.StackTrace(), opts)
+	}
+
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return nil
+	}
+
+	trace := method.Call(nil)[0]
+	if trace.Kind() != reflect.Slice {
+		return nil
+	}
+
+	switch trace.Type().Elem().Kind() {
+	case reflect.Uintptr:
+		pcs := make([]uintptr, trace.Len())
+		for i := range pcs {
+			pcs[i] = uintptr(trace.Index(i).Uint())
+		}
+
+		return formatFrames(pcs, opts)
+	case reflect.String:
+		stack := make([]string, 0, trace.Len())
+		for i := range trace.Len() {
+			if len(stack) == opts.StackDepth {
+				break
+			}
+
+			stack = append(stack, trace.Index(i).String())
+		}
+
+		return stack
+	default:
+		return nil
+	}
+}
+
+// captureStack captures the stack trace of its caller, without the leading frames of this package.
+func captureStack(opts *ExceptionOptions) []string {
+	pcs := make([]uintptr, opts.StackDepth+32)
 	n := runtime.Callers(2, pcs)
-	frames := runtime.CallersFrames(pcs[:n])
+	frames := callersFrames(pcs[:n])
+
+	for len(frames) > 1 && isInternalFrame(frames[0]) {
+		frames = frames[1:]
+	}
+
+	return formatStack(frames, opts)
+}
+
+// captureStackTrace captures the current stack trace as a slice of strings.
+func captureStackTrace() []string {
+	return captureStack(&ExceptionOptions{StackDepth: DefaultStackDepth})
+}
+
+// formatFrames formats the frames of program counters.
+func formatFrames(pcs []uintptr, opts *ExceptionOptions) []string {
+	return formatStack(callersFrames(pcs), opts)
+}
+
+// callersFrames resolves program counters into frames, including inlined calls.
+func callersFrames(pcs []uintptr) []runtime.Frame {
+	var frames []runtime.Frame
+
+	if len(pcs) == 0 {
+		return frames
+	}
+
+	iter := runtime.CallersFrames(pcs)
 
 	for {
-		frame, more := frames.Next()
-		stack = append(stack, frame.Function+" at "+frame.File+":"+string(rune(frame.Line)))
+		frame, more := iter.Next()
+		if frame.Function != "" {
+			frames = append(frames, frame)
+		}
 
 		if !more {
+			return frames
+		}
+	}
+}
+
+// formatStack formats frames as "function at file:line", dropping runtime
+// frames unless configured otherwise.
+func formatStack(frames []runtime.Frame, opts *ExceptionOptions) []string {
+	stack := []string{}
+
+	for _, frame := range frames {
+		if len(stack) == opts.StackDepth {
 			break
 		}
+
+		if !opts.IncludeRuntimeFrames && strings.HasPrefix(frame.Function, "runtime.") {
+			continue
+		}
+
+		stack = append(stack, fmt.Sprintf("%s at %s:%d", frame.Function, frame.File, frame.Line))
 	}
 
 	return stack
 }
 
+// withNotes returns a copy of the exception with the notes appended to its message.
+func (e *Exception) withNotes(notes []string) *Exception {
+	if len(notes) == 0 {
+		return e
+	}
+
+	noted := *e
+	noted.Message = strings.Join(append([]string{e.Message}, notes...), "\n")
+
+	return &noted
+}
+
+// getExceptionName returns the name of the error's exception, preferring ExceptionNamer.
+func getExceptionName(err error) string {
+	if namer, ok := err.(ExceptionNamer); ok {
+		if name := namer.ExceptionName(); name != "" {
+			return name
+		}
+	}
+
+	return getTypeName(err)
+}
+
 // getTypeName gets the type name of an error.
 func getTypeName(err error) string {
 	if err == nil {
@@ -64,6 +463,20 @@ func getTypeName(err error) string {
 	return fmt.Sprintf("%T", err)
 }
 
+// getCauses retrieves the causes of an error wrapping one or multiple errors.
+func getCauses(err error) []error {
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		if cause := e.Unwrap(); cause != nil {
+			return []error{cause}
+		}
+	case interface{ Unwrap() []error }:
+		return slices.DeleteFunc(slices.Clone(e.Unwrap()), func(cause error) bool { return cause == nil })
+	}
+
+	return nil
+}
+
 // getCause retrieves the cause of an error if it supports wrapping.
 func getCause(err error) error {
 	type causer interface {
@@ -3,9 +3,57 @@ package dagsterpipes
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
 	"testing"
 )
 
+// newTestStackError returns an error annotated with its origin.
+func newTestStackError() error {
+	return WithStack(errors.New("origin"))
+}
+
+// testValidationError is an error with a custom exception name.
+type testValidationError struct {
+	field string
+}
+
+func (e *testValidationError) Error() string { return "invalid " + e.field }
+
+func (e *testValidationError) ExceptionName() string { return "ValidationError" }
+
+// testCyclicError is an error whose chain of causes forms a cycle.
+type testCyclicError struct {
+	next error
+}
+
+func (e *testCyclicError) Error() string { return "cyclic" }
+
+func (e *testCyclicError) Unwrap() error { return e.next }
+
+// testFrame and testStackTrace mimic the stack traces of github.com/pkg/errors.
+type testFrame uintptr
+
+type testStackTrace []testFrame
+
+type testFramesError struct {
+	frames testStackTrace
+}
+
+func (e *testFramesError) Error() string { return "frames" }
+
+func (e *testFramesError) StackTrace() testStackTrace { return e.frames }
+
+type testLinesError struct {
+	lines []string
+}
+
+func (e *testLinesError) Error() string { return "lines" }
+
+func (e *testLinesError) StackTrace() []string { return e.lines }
+
 func TestException(t *testing.T) {
 	t.Run("NewException", func(t *testing.T) {
 		t.Run("NoError", func(t *testing.T) {
@@ -96,6 +144,175 @@ func TestException(t *testing.T) {
 		}
 	})
 
+	t.Run("StackTrace", func(t *testing.T) {
+		t.Run("Format", func(t *testing.T) {
+			exception := NewException(errors.New("boom"), true)
+
+			if !strings.Contains(exception.Stack[0], "TestException") {
+				t.Fatalf("Expected first frame in the test, got %s", exception.Stack[0])
+			}
+
+			if !regexp.MustCompile(`^\S+ at \S+_test\.go:\d+$`).MatchString(exception.Stack[0]) {
+				t.Fatalf("Expected 'function at file:line' frame, got %q", exception.Stack[0])
+			}
+
+			for _, frame := range exception.Stack {
+				if strings.HasPrefix(frame, "runtime.") {
+					t.Fatalf("Expected runtime frames to be filtered, got %s", frame)
+				}
+			}
+		})
+
+		t.Run("Depth", func(t *testing.T) {
+			exception := NewException(errors.New("boom"), true, func(o *ExceptionOptions) {
+				o.StackDepth = 1
+			})
+
+			if len(exception.Stack) != 1 {
+				t.Fatalf("Expected 1 frame, got %d", len(exception.Stack))
+			}
+		})
+
+		t.Run("WithStack", func(t *testing.T) {
+			err := fmt.Errorf("wrapped: %w", newTestStackError())
+			exception := NewException(err, true)
+
+			if !strings.Contains(exception.Stack[0], "newTestStackError") {
+				t.Fatalf("Expected origin stack trace, got %s", exception.Stack[0])
+			}
+
+			if exception.Cause == nil || len(exception.Cause.Stack) == 0 {
+				t.Fatal("Expected cause to carry its stack trace")
+			}
+
+			if WithStack(nil) != nil {
+				t.Fatal("Expected nil error")
+			}
+		})
+
+		t.Run("StackTraceMethod", func(t *testing.T) {
+			pcs := make([]uintptr, 8)
+			n := runtime.Callers(1, pcs)
+
+			frames := make(testStackTrace, n)
+			for i, pc := range pcs[:n] {
+				frames[i] = testFrame(pc)
+			}
+
+			exception := NewException(&testFramesError{frames: frames}, true)
+			if !strings.Contains(exception.Stack[0], "TestException") {
+				t.Fatalf("Expected extracted stack trace, got %v", exception.Stack)
+			}
+
+			exception = NewException(&testLinesError{lines: []string{"main.run at main.go:3"}}, true)
+			if len(exception.Stack) != 1 || exception.Stack[0] != "main.run at main.go:3" {
+				t.Fatalf("Expected extracted stack trace, got %v", exception.Stack)
+			}
+		})
+	})
+
+	t.Run("MultipleCauses", func(t *testing.T) {
+		t.Run("Join", func(t *testing.T) {
+			err := errors.Join(errors.New("a"), errors.New("b"), errors.New("c"))
+			exception := NewException(err, true)
+
+			if exception.Cause == nil || exception.Cause.Message != "a" {
+				t.Fatalf("Expected first cause 'a', got %+v", exception.Cause)
+			}
+
+			var messages []string
+			for context := exception.Context; context != nil; context = context.Context {
+				if context.Name != "*errors.joinError" || context.Cause == nil {
+					t.Fatalf("Unexpected context: %+v", context)
+				}
+
+				messages = append(messages, context.Message+": "+context.Cause.Message)
+			}
+
+			expected := []string{"cause 2 of 3: b", "cause 3 of 3: c"}
+			if !slices.Equal(messages, expected) {
+				t.Fatalf("Expected contexts %v, got %v", expected, messages)
+			}
+
+			if err := ValidateMessage(Message{DagsterPipesVersion: ProtocolVersion, Method: MethodClosed, Params: &Closed{Exception: exception}}); err != nil {
+				t.Fatalf("Unexpected schema error: %v", err)
+			}
+		})
+
+		t.Run("Errorf", func(t *testing.T) {
+			err := fmt.Errorf("both: %w, %w", errors.New("a"), fmt.Errorf("b: %w", errors.New("root")))
+			exception := NewException(err, false)
+
+			if exception.Cause.Message != "a" || exception.Context.Cause.Cause.Message != "root" {
+				t.Fatalf("Unexpected exception: %+v", exception)
+			}
+		})
+
+		t.Run("WithStack", func(t *testing.T) {
+			err := fmt.Errorf("%w; %w", newTestStackError(), errors.New("b"))
+			exception := NewException(err, true)
+
+			if !strings.Contains(exception.Stack[0], "newTestStackError") {
+				t.Fatalf("Expected stack trace of the first cause, got %s", exception.Stack[0])
+			}
+
+			if exception.Cause == nil || !slices.Equal(exception.Cause.Stack, exception.Stack) {
+				t.Fatal("Expected root stack trace to match the first cause")
+			}
+		})
+
+		t.Run("Cycle", func(t *testing.T) {
+			err := &testCyclicError{}
+			err.next = &testCyclicError{next: err}
+
+			exception := NewException(err, false)
+			if exception.Cause == nil || exception.Cause.Cause == nil || exception.Cause.Cause.Message != "cyclic error chain" {
+				t.Fatalf("Expected cycle to be detected, got %+v", exception)
+			}
+
+			if exception := NewException(err, true); len(exception.Stack) == 0 {
+				t.Fatal("Expected stack trace for cyclic error chain")
+			}
+		})
+
+		t.Run("Depth", func(t *testing.T) {
+			err := errors.New("root")
+			for range 10 {
+				err = fmt.Errorf("wrapped: %w", err)
+			}
+
+			exception := NewException(err, false, func(o *ExceptionOptions) {
+				o.MaxDepth = 3
+			})
+
+			depth := 0
+			for e := exception; e.Cause != nil; e = e.Cause {
+				depth++
+			}
+
+			if depth != 3 || !strings.Contains(exception.Cause.Cause.Cause.Message, "truncated") {
+				t.Fatalf("Expected chain truncated at depth 3, got depth %d", depth)
+			}
+		})
+	})
+
+	t.Run("NamesAndNotes", func(t *testing.T) {
+		err := fmt.Errorf("load config: %w", WithNotes(WithStack(&testValidationError{field: "port"}), "file: app.yaml", "line: 3"))
+		exception := NewException(err, false)
+
+		if exception.Name != "*fmt.wrapError" {
+			t.Fatalf("Expected Go type name, got %s", exception.Name)
+		}
+
+		if exception.Cause.Name != "ValidationError" {
+			t.Fatalf("Expected name ValidationError, got %s", exception.Cause.Name)
+		}
+
+		if exception.Cause.Message != "invalid port\nfile: app.yaml\nline: 3" {
+			t.Fatalf("Expected notes in message, got %q", exception.Cause.Message)
+		}
+	})
+
 	t.Run("getTypeName", func(t *testing.T) {
 		err := errors.New("some error")
 		typeName := getTypeName(err)
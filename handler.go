@@ -0,0 +1,196 @@
+package dagsterpipes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SlogHandlerOptions defines configuration options for creating a slog handler.
+type SlogHandlerOptions struct {
+	Level slog.Leveler // Minimum level of records forwarded to Dagster. Defaults to slog.LevelInfo.
+	Next  slog.Handler // Optional downstream handler, e.g. for local output.
+}
+
+// slogHandler is a slog.Handler forwarding records as "log" messages of a Context.
+type slogHandler[T any] struct {
+	context *Context[T]  // Context receiving the log messages.
+	level   slog.Leveler // Minimum level of forwarded records.
+	next    slog.Handler // Optional downstream handler.
+	attrs   string       // Preformatted attributes added by WithAttrs.
+	prefix  string       // Key prefix of the open groups, e.g. "request.".
+}
+
+// NewSlogHandler creates a slog.Handler that sends records, including their
+// attributes and groups, as "log" messages through the context. Attributes are
+// appended to the message as key=value pairs. Records are also passed to the
+// Next handler, if configured.
+//
+// Messages logged by the context itself, e.g. by LogInfo, are not forwarded
+// again, so the handler can back the context's own logger or slog.SetDefault.
+func NewSlogHandler[T any](c *Context[T], optFns ...func(o *SlogHandlerOptions)) slog.Handler {
+	opts := SlogHandlerOptions{
+		Level: slog.LevelInfo,
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	return &slogHandler[T]{
+		context: c,
+		level:   opts.Level,
+		next:    opts.Next,
+	}
+}
+
+// Enabled reports whether the record is forwarded to Dagster or the downstream handler.
+func (h *slogHandler[T]) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level() || (h.next != nil && h.next.Enabled(ctx, level))
+}
+
+// Handle forwards the record to Dagster and the downstream handler.
+func (h *slogHandler[T]) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+
+	if h.next != nil && h.next.Enabled(ctx, record.Level) {
+		errs = append(errs, h.next.Handle(ctx, record.Clone()))
+	}
+
+	if record.Level >= h.level.Level() && !isPipesLog(ctx) {
+		errs = append(errs, h.context.sendLog(ctx, record.Level, h.format(record)))
+	}
+
+	return errors.Join(errs...)
+}
+
+// WithAttrs returns a handler adding the attributes to all records.
+func (h *slogHandler[T]) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	var b strings.Builder
+
+	b.WriteString(h.attrs)
+
+	for _, attr := range attrs {
+		appendAttr(&b, h.prefix, attr)
+	}
+
+	clone := *h
+	clone.attrs = b.String()
+
+	if h.next != nil {
+		clone.next = h.next.WithAttrs(attrs)
+	}
+
+	return &clone
+}
+
+// WithGroup returns a handler qualifying the keys of subsequent attributes with the group name.
+func (h *slogHandler[T]) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	clone := *h
+	clone.prefix = h.prefix + name + "."
+
+	if h.next != nil {
+		clone.next = h.next.WithGroup(name)
+	}
+
+	return &clone
+}
+
+// format formats the message and attributes of a record.
+func (h *slogHandler[T]) format(record slog.Record) string {
+	var b strings.Builder
+
+	b.WriteString(record.Message)
+	b.WriteString(h.attrs)
+
+	record.Attrs(func(attr slog.Attr) bool {
+		appendAttr(&b, h.prefix, attr)
+		return true
+	})
+
+	return b.String()
+}
+
+// appendAttr appends an attribute as " key=value", flattening groups into dotted keys.
+func appendAttr(b *strings.Builder, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+
+		for _, member := range attr.Value.Group() {
+			appendAttr(b, prefix, member)
+		}
+
+		return
+	}
+
+	b.WriteString(" ")
+	b.WriteString(prefix + attr.Key)
+	b.WriteString("=")
+	b.WriteString(formatAttrValue(attr.Value))
+}
+
+// formatAttrValue formats an attribute value, quoting strings where necessary.
+func formatAttrValue(value slog.Value) string {
+	var s string
+
+	switch value.Kind() {
+	case slog.KindString:
+		s = value.String()
+	case slog.KindTime:
+		return value.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			s = err.Error()
+		} else {
+			s = fmt.Sprint(value.Any())
+		}
+	default:
+		return value.String()
+	}
+
+	if s == "" || strings.IndexFunc(s, needsQuoting) >= 0 {
+		return strconv.Quote(s)
+	}
+
+	return s
+}
+
+// needsQuoting reports whether a rune requires an attribute value to be quoted.
+func needsQuoting(r rune) bool {
+	return r == ' ' || r == '=' || r == '"' || !unicode.IsPrint(r)
+}
+
+// pipesLogKey marks contexts of records logged by a Context itself.
+type pipesLogKey struct{}
+
+// withPipesLog marks the context as belonging to a record logged by a Context itself.
+func withPipesLog(ctx context.Context) context.Context {
+	return context.WithValue(ctx, pipesLogKey{}, true)
+}
+
+// isPipesLog reports whether the context belongs to a record logged by a Context itself.
+func isPipesLog(ctx context.Context) bool {
+	marked, _ := ctx.Value(pipesLogKey{}).(bool)
+	return marked
+}
@@ -0,0 +1,96 @@
+package dagsterpipes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	t.Run("AttrsAndGroups", func(t *testing.T) {
+		pc, channel := newTestContext(t, []string{"asset"})
+
+		logger := slog.New(NewSlogHandler(pc)).With("run", "r1").WithGroup("req")
+
+		logger.Info("handled request", "path", "/a b", slog.Group("user", "id", 7), "err", errors.New("boom"))
+		logger.Debug("filtered")
+		logger.Warn("slow")
+
+		messages := channel.Messages(MethodLog)
+		if len(messages) != 2 {
+			t.Fatalf("Expected 2 log messages, got %d", len(messages))
+		}
+
+		params, _ := messages[0]["params"].(map[string]any)
+
+		expected := `handled request run=r1 req.path="/a b" req.user.id=7 req.err=boom`
+		if params["message"] != expected || params["level"] != string(LogLevelInfo) {
+			t.Fatalf("Unexpected log message: %v", params)
+		}
+
+		params, _ = messages[1]["params"].(map[string]any)
+		if params["message"] != "slow run=r1" || params["level"] != string(LogLevelWarning) {
+			t.Fatalf("Unexpected log message: %v", params)
+		}
+	})
+
+	t.Run("NextAndLevel", func(t *testing.T) {
+		pc, channel := newTestContext(t, []string{"asset"})
+
+		var buf bytes.Buffer
+
+		next := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+		logger := slog.New(NewSlogHandler(pc, func(o *SlogHandlerOptions) {
+			o.Level = slog.LevelWarn
+			o.Next = next
+		}))
+
+		logger.Debug("local only", "key", "value")
+		logger.Error("both")
+
+		if !strings.Contains(buf.String(), "local only") || !strings.Contains(buf.String(), "both") {
+			t.Fatalf("Expected both records in local output, got %q", buf.String())
+		}
+
+		messages := channel.Messages(MethodLog)
+		if len(messages) != 1 {
+			t.Fatalf("Expected 1 log message, got %d", len(messages))
+		}
+	})
+
+	t.Run("NoDuplicates", func(t *testing.T) {
+		var handler slog.Handler
+
+		pc, channel := newTestContext(t, []string{"asset"}, func(o *Options[map[string]any]) {
+			o.Logger = slog.New(slogHandlerFunc(func() slog.Handler { return handler }))
+		})
+
+		handler = NewSlogHandler(pc)
+
+		if err := pc.LogInfo("once"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if messages := channel.Messages(MethodLog); len(messages) != 1 {
+			t.Fatalf("Expected 1 log message, got %d", len(messages))
+		}
+	})
+}
+
+// slogHandlerFunc is a slog.Handler delegating to a lazily created handler.
+type slogHandlerFunc func() slog.Handler
+
+func (f slogHandlerFunc) Enabled(ctx context.Context, level slog.Level) bool {
+	return f().Enabled(ctx, level)
+}
+
+func (f slogHandlerFunc) Handle(ctx context.Context, record slog.Record) error {
+	return f().Handle(ctx, record)
+}
+
+func (f slogHandlerFunc) WithAttrs(attrs []slog.Attr) slog.Handler { return f().WithAttrs(attrs) }
+
+func (f slogHandlerFunc) WithGroup(name string) slog.Handler { return f().WithGroup(name) }
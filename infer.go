@@ -0,0 +1,128 @@
+package dagsterpipes
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// metadataInferences holds the registered inference overrides by Go type.
+var metadataInferences sync.Map // map[reflect.Type]func(v any) MetadataValue
+
+// RegisterMetadataInference overrides the inferred metadata value for values of type V.
+// Registering a function for the same type again replaces the previous one.
+func RegisterMetadataInference[V any](fn func(v V) MetadataValue) {
+	metadataInferences.Store(reflect.TypeFor[V](), func(v any) MetadataValue {
+		typed, _ := v.(V)
+		return fn(typed)
+	})
+}
+
+// InferMetadataValue converts a Go value into a typed metadata value:
+//
+//   - MetadataValue is returned as is, nil values become null
+//   - MetadataMarshaler implementations convert themselves
+//   - Table, TableSchema and TableColumnLineage become their table metadata types
+//   - types registered with RegisterMetadataInference use the registered function
+//   - time.Time becomes timestamp and *url.URL becomes url
+//   - fmt.Stringer and error values become text
+//   - bools become bool, integers become int and strings become text
+//   - finite floats become float, NaN and infinities become text ("NaN", "+Inf", "-Inf")
+//   - structs, maps, slices and arrays become json
+//
+// Pointers are dereferenced. Returns an error for values that cannot be represented, such as funcs.
+func InferMetadataValue(value any) (MetadataValue, error) {
+	if value == nil {
+		return NullMetadata(), nil
+	}
+
+	if v, ok := value.(MetadataValue); ok {
+		return v, nil
+	}
+
+	if marshaler, ok := value.(MetadataMarshaler); ok {
+		if rv := reflect.ValueOf(value); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return NullMetadata(), nil
+		}
+
+		return marshaler.MarshalMetadata()
+	}
+
+	if fn, ok := metadataInferences.Load(reflect.TypeOf(value)); ok {
+		infer, _ := fn.(func(v any) MetadataValue)
+		return infer(value), nil
+	}
+
+	switch v := value.(type) {
+	case time.Time:
+		return TimestampMetadata(v), nil
+	case *time.Time:
+		if v == nil {
+			return NullMetadata(), nil
+		}
+
+		return TimestampMetadata(*v), nil
+	case Table:
+		return MetadataValue{RawValue: v, Type: MetadataTypeTable}, nil
+	case TableSchema:
+		return TableSchemaMetadata(v), nil
+	case TableColumnLineage:
+		return TableColumnLineageMetadata(v), nil
+	case url.URL:
+		return URLMetadata(v.String()), nil
+	case *url.URL:
+		if v == nil {
+			return NullMetadata(), nil
+		}
+
+		return URLMetadata(v.String()), nil
+	case fmt.Stringer:
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return NullMetadata(), nil
+		}
+
+		return TextMetadata(v.String()), nil
+	case error:
+		return TextMetadata(v.Error()), nil
+	}
+
+	rv := reflect.ValueOf(value)
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		return BoolMetadata(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return IntMetadata(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return IntMetadata(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return TextMetadata(strconv.FormatFloat(f, 'g', -1, 64)), nil
+		}
+
+		return FloatMetadata(f), nil
+	case reflect.String:
+		return TextMetadata(rv.String()), nil
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return NullMetadata(), nil
+		}
+
+		return InferMetadataValue(rv.Elem().Interface())
+	case reflect.Map, reflect.Slice:
+		if rv.IsNil() {
+			return NullMetadata(), nil
+		}
+
+		return JSONMetadata(value), nil
+	case reflect.Struct, reflect.Array:
+		return JSONMetadata(value), nil
+	default:
+		return MetadataValue{}, fmt.Errorf("cannot infer metadata type of %T", value)
+	}
+}
@@ -0,0 +1,48 @@
+package dagsterpipes
+
+import "log/slog"
+
+// LevelCritical is the slog level of critical messages, above slog.LevelError.
+const LevelCritical = slog.Level(12)
+
+// LogLevel represents a log level as understood by Dagster.
+type LogLevel string
+
+const (
+	// LogLevelDebug is the Dagster debug level.
+	LogLevelDebug LogLevel = "DEBUG"
+
+	// LogLevelInfo is the Dagster info level.
+	LogLevelInfo LogLevel = "INFO"
+
+	// LogLevelWarning is the Dagster warning level.
+	LogLevelWarning LogLevel = "WARNING"
+
+	// LogLevelError is the Dagster error level.
+	LogLevelError LogLevel = "ERROR"
+
+	// LogLevelCritical is the Dagster critical level.
+	LogLevelCritical LogLevel = "CRITICAL"
+)
+
+// LevelMapper maps slog levels to Dagster log levels.
+type LevelMapper func(level slog.Level) LogLevel
+
+// DefaultLevelMapper maps a slog level to the nearest Dagster level. Levels
+// exactly halfway between two Dagster levels map to the more severe one, so
+// that messages are never under-reported, e.g. slog.LevelWarn+3 maps to ERROR,
+// slog.LevelInfo+2 to WARNING and slog.LevelDebug-4 to DEBUG.
+func DefaultLevelMapper(level slog.Level) LogLevel {
+	switch {
+	case level >= (slog.LevelError+LevelCritical)/2:
+		return LogLevelCritical
+	case level >= (slog.LevelWarn+slog.LevelError)/2:
+		return LogLevelError
+	case level >= (slog.LevelInfo+slog.LevelWarn)/2:
+		return LogLevelWarning
+	case level >= (slog.LevelDebug+slog.LevelInfo)/2:
+		return LogLevelInfo
+	default:
+		return LogLevelDebug
+	}
+}
@@ -0,0 +1,102 @@
+package dagsterpipes
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestDefaultLevelMapper(t *testing.T) {
+	tests := []struct {
+		level    slog.Level
+		expected LogLevel
+	}{
+		{slog.LevelDebug - 4, LogLevelDebug},
+		{slog.LevelDebug, LogLevelDebug},
+		{slog.LevelDebug + 1, LogLevelDebug},
+		{slog.LevelInfo - 2, LogLevelInfo},
+		{slog.LevelInfo - 1, LogLevelInfo},
+		{slog.LevelInfo, LogLevelInfo},
+		{slog.LevelInfo + 1, LogLevelInfo},
+		{slog.LevelInfo + 2, LogLevelWarning},
+		{slog.LevelWarn, LogLevelWarning},
+		{slog.LevelWarn + 1, LogLevelWarning},
+		{slog.LevelWarn + 3, LogLevelError},
+		{slog.LevelError, LogLevelError},
+		{slog.LevelError + 1, LogLevelError},
+		{slog.LevelError + 2, LogLevelCritical},
+		{LevelCritical, LogLevelCritical},
+		{LevelCritical + 4, LogLevelCritical},
+	}
+
+	for _, tt := range tests {
+		if actual := DefaultLevelMapper(tt.level); actual != tt.expected {
+			t.Errorf("Expected %s for level %s, got %s", tt.expected, tt.level, actual)
+		}
+	}
+}
+
+func TestContextLogLevels(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		pc, channel := newTestContext(t, []string{"asset"})
+
+		logs := []func(string) error{pc.LogDebug, pc.LogInfo, pc.LogWarn, pc.LogError, pc.LogCritical}
+		for _, log := range logs {
+			if err := log("message"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		if err := pc.Log(slog.LevelWarn+1, "message"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := []LogLevel{LogLevelDebug, LogLevelInfo, LogLevelWarning, LogLevelError, LogLevelCritical, LogLevelWarning}
+
+		messages := channel.Messages(MethodLog)
+		if len(messages) != len(expected) {
+			t.Fatalf("Expected %d log messages, got %d", len(expected), len(messages))
+		}
+
+		for i, msg := range messages {
+			params, _ := msg["params"].(map[string]any)
+			if params["level"] != string(expected[i]) {
+				t.Errorf("Expected level %s, got %v", expected[i], params["level"])
+			}
+		}
+	})
+
+	t.Run("CustomMapper", func(t *testing.T) {
+		pc, channel := newTestContext(t, []string{"asset"}, func(o *Options[map[string]any]) {
+			o.LevelMapper = func(level slog.Level) LogLevel {
+				if level < slog.LevelInfo {
+					return LogLevelInfo
+				}
+
+				return DefaultLevelMapper(level)
+			}
+		})
+
+		if err := pc.LogDebug("message"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		params, _ := channel.Messages(MethodLog)[0]["params"].(map[string]any)
+		if params["level"] != string(LogLevelInfo) {
+			t.Fatalf("Expected level INFO, got %v", params["level"])
+		}
+	})
+	t.Run("NilMapper", func(t *testing.T) {
+		pc, channel := newTestContext(t, []string{"asset"}, func(o *Options[map[string]any]) {
+			o.LevelMapper = nil
+		})
+
+		if err := pc.LogWarn("message"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		params, _ := channel.Messages(MethodLog)[0]["params"].(map[string]any)
+		if params["level"] != string(LogLevelWarning) {
+			t.Fatalf("Expected fallback to the default mapper, got %v", params["level"])
+		}
+	})
+}
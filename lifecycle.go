@@ -0,0 +1,98 @@
+package dagsterpipes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrContextClosed is returned when a message is sent after the pipes context
+// started closing.
+var ErrContextClosed = errors.New("cannot send message after pipes context is closed")
+
+// contextState is the lifecycle state of a Context. The state only advances,
+// except for a failed "closed" message, which returns the context to stateOpen
+// so that Close can be retried.
+type contextState int
+
+const (
+	// stateOpening is the state while the "opened" message is sent.
+	stateOpening contextState = iota
+
+	// stateOpen is the state in which messages can be sent.
+	stateOpen
+
+	// stateClosing is the state while the "closed" message is sent. Other messages are rejected.
+	stateClosing
+
+	// stateClosed is the final state. All messages are rejected.
+	stateClosed
+)
+
+// String returns the name of the state.
+func (s contextState) String() string {
+	switch s {
+	case stateOpening:
+		return "opening"
+	case stateOpen:
+		return "open"
+	case stateClosing:
+		return "closing"
+	case stateClosed:
+		return "closed"
+	default:
+		return fmt.Sprintf("contextState(%d)", int(s))
+	}
+}
+
+// beginClose moves the context from stateOpen to stateClosing and returns the
+// exception to report. If another Close is in progress, it waits for it to
+// finish. It returns false if the context is already closed.
+func (c *Context[T]) beginClose(ctx context.Context) (*Exception, bool, error) {
+	for {
+		c.mu.Lock()
+
+		switch c.state {
+		case stateClosed:
+			c.mu.Unlock()
+			return nil, false, nil
+		case stateClosing:
+			closing := c.closing
+			c.mu.Unlock()
+
+			select {
+			case <-closing:
+				continue
+			case <-ctx.Done():
+				return nil, false, ctx.Err()
+			}
+		}
+
+		c.state = stateClosing
+		c.closing = make(chan struct{})
+
+		exception := c.exception
+		if exception != nil {
+			exception = exception.withNotes(c.exceptionNotes)
+		}
+
+		c.mu.Unlock()
+
+		return exception, true, nil
+	}
+}
+
+// endClose leaves stateClosing, either to stateClosed or, if the "closed"
+// message could not be sent, back to stateOpen, and wakes up waiting closers.
+func (c *Context[T]) endClose(closed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if closed {
+		c.state = stateClosed
+	} else {
+		c.state = stateOpen
+	}
+
+	close(c.closing)
+}
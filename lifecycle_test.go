@@ -0,0 +1,194 @@
+package dagsterpipes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestContextLifecycle(t *testing.T) {
+	t.Run("LateWrites", func(t *testing.T) {
+		pc, channel := newTestContext(t, []string{"asset"})
+
+		if err := pc.Close(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if err := pc.LogInfo("late"); !errors.Is(err, ErrContextClosed) {
+			t.Fatalf("Expected ErrContextClosed, got %v", err)
+		}
+
+		if err := pc.ReportAssetMaterialization(&AssetMaterialization{}); !errors.Is(err, ErrContextClosed) {
+			t.Fatalf("Expected ErrContextClosed, got %v", err)
+		}
+
+		// A rejected report must not use up the asset key.
+		pc.mu.RLock()
+		_, reserved := pc.materializedKeys["asset"]
+		pc.mu.RUnlock()
+
+		if reserved {
+			t.Fatal("Expected reservation of failed report to be released")
+		}
+
+		if len(channel.Messages(MethodLog)) != 0 {
+			t.Fatal("Unexpected late message")
+		}
+	})
+
+	t.Run("RejectedDuplicate", func(t *testing.T) {
+		pc, _ := newTestContext(t, []string{"asset"})
+
+		if err := pc.ReportAssetMaterialization(&AssetMaterialization{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		// The missing path would fail hashing, so the duplicate must be rejected before.
+		duplicate := &AssetMaterialization{}
+
+		err := pc.ReportAssetMaterialization(duplicate, func(o *ReportAssetMaterializationOptions) {
+			o.DataVersionPaths = []string{filepath.Join(t.TempDir(), "missing")}
+		})
+		if err == nil || !strings.Contains(err.Error(), "already been materialized") {
+			t.Fatalf("Expected duplicate error, got %v", err)
+		}
+
+		if duplicate.AssetKey != "" || duplicate.DataVersion != "" {
+			t.Fatalf("Expected rejected materialization to be left untouched, got %+v", duplicate)
+		}
+	})
+
+	t.Run("RetryClose", func(t *testing.T) {
+		pc, channel := newTestContext(t, []string{"asset"})
+
+		channel.mu.Lock()
+		channel.err = errors.New("unavailable")
+		channel.mu.Unlock()
+
+		if err := pc.Close(); err == nil {
+			t.Fatal("Expected close error")
+		}
+
+		if pc.IsClosed() {
+			t.Fatal("Expected context to stay open after failed close")
+		}
+
+		channel.mu.Lock()
+		channel.err = nil
+		channel.mu.Unlock()
+
+		if err := pc.Close(); err != nil || !pc.IsClosed() {
+			t.Fatalf("Expected retried close to succeed, got %v", err)
+		}
+	})
+
+	t.Run("ConcurrentClose", func(t *testing.T) {
+		for range 20 {
+			pc, channel := newTestContext(t, []string{"asset"})
+
+			var wg sync.WaitGroup
+
+			for range 8 {
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+
+					if err := pc.Close(); err != nil {
+						t.Errorf("Unexpected error: %v", err)
+					}
+				}()
+			}
+
+			wg.Wait()
+
+			if messages := channel.Messages(MethodClosed); len(messages) != 1 {
+				t.Fatalf("Expected 1 closed message, got %d", len(messages))
+			}
+		}
+	})
+
+	t.Run("ConcurrentWrites", func(t *testing.T) {
+		for range 20 {
+			assetKeys := make([]string, 16)
+			for i := range assetKeys {
+				assetKeys[i] = fmt.Sprintf("asset%d", i)
+			}
+
+			pc, channel := newTestContext(t, assetKeys)
+
+			var wg sync.WaitGroup
+
+			for _, assetKey := range assetKeys {
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+
+					err := pc.ReportAssetMaterialization(&AssetMaterialization{AssetKey: assetKey})
+					if err != nil && !errors.Is(err, ErrContextClosed) {
+						t.Errorf("Unexpected error: %v", err)
+					}
+				}()
+			}
+
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				if err := pc.CloseContext(context.Background()); err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			}()
+
+			wg.Wait()
+
+			channel.mu.Lock()
+			last := channel.messages[len(channel.messages)-1]
+			channel.mu.Unlock()
+
+			if last["method"] != string(MethodClosed) {
+				t.Fatalf("Expected closed to be the last message, got %v", last["method"])
+			}
+		}
+	})
+
+	t.Run("ConcurrentDuplicates", func(t *testing.T) {
+		for range 20 {
+			pc, channel := newTestContext(t, []string{"asset"})
+
+			var wg sync.WaitGroup
+
+			for range 8 {
+				wg.Add(2)
+
+				go func() {
+					defer wg.Done()
+
+					_ = pc.ReportAssetMaterialization(&AssetMaterialization{})
+				}()
+
+				go func() {
+					defer wg.Done()
+
+					_ = pc.ReportAssetCheck(&AssetCheck{CheckName: "check", Passed: true})
+				}()
+			}
+
+			wg.Wait()
+
+			if messages := channel.Messages(MethodReportAssetMaterialization); len(messages) != 1 {
+				t.Fatalf("Expected 1 materialization, got %d", len(messages))
+			}
+
+			if messages := channel.Messages(MethodReportAssetCheck); len(messages) != 1 {
+				t.Fatalf("Expected 1 asset check, got %d", len(messages))
+			}
+		}
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 )
 
 // ContextData represents the runtime context for a Dagster Pipes process,
@@ -36,9 +37,91 @@ type ContextParams[T any] struct {
 }
 
 // MessagesParams represents parameters for managing messages between Dagster Pipes processes.
+// Dagster sends different param shapes depending on the message reader in use, e.g.
+// {"path": ...}, {"stdio": ...} or {"bucket": ..., "key_prefix": ...}. All received keys
+// are preserved in Params, the most common ones are additionally exposed as fields.
 type MessagesParams struct {
-	Stdio string `json:"stdio"` // Configuration for standard I/O messaging.
-	Path  string `json:"path"`  // File path for message exchange.
+	Stdio  string         `json:"stdio"` // Configuration for standard I/O messaging.
+	Path   string         `json:"path"`  // File path for message exchange.
+	Params map[string]any `json:"-"`     // All received parameters, including unknown keys.
+}
+
+// UnmarshalJSON decodes the messages params, preserving arbitrary keys.
+func (p *MessagesParams) UnmarshalJSON(data []byte) error {
+	var params map[string]any
+	if err := json.Unmarshal(data, &params); err != nil {
+		return err
+	}
+
+	p.Params = params
+	p.Stdio, _ = params["stdio"].(string)
+	p.Path, _ = params["path"].(string)
+
+	return nil
+}
+
+// MarshalJSON encodes the messages params including all preserved keys.
+func (p MessagesParams) MarshalJSON() ([]byte, error) {
+	params := make(map[string]any, len(p.Params)+2)
+	for key, value := range p.Params {
+		params[key] = value
+	}
+
+	if p.Stdio != "" {
+		params["stdio"] = p.Stdio
+	}
+
+	if p.Path != "" {
+		params["path"] = p.Path
+	}
+
+	return json.Marshal(params)
+}
+
+// Get returns the value of the given key and whether it is present.
+func (p *MessagesParams) Get(key string) (any, bool) {
+	switch {
+	case key == "stdio" && p.Stdio != "":
+		return p.Stdio, true
+	case key == "path" && p.Path != "":
+		return p.Path, true
+	}
+
+	value, ok := p.Params[key]
+
+	return value, ok
+}
+
+// Has reports whether the given key is present.
+func (p *MessagesParams) Has(key string) bool {
+	_, ok := p.Get(key)
+	return ok
+}
+
+// String returns the value of the given key as a string, or "" if it is absent or not a string.
+func (p *MessagesParams) String(key string) string {
+	value, _ := p.Get(key)
+	s, _ := value.(string)
+
+	return s
+}
+
+// Keys returns the sorted list of keys present in the params.
+func (p *MessagesParams) Keys() []string {
+	keys := make([]string, 0, len(p.Params)+2)
+	for key := range p.Params {
+		keys = append(keys, key)
+	}
+
+	for _, key := range []string{"stdio", "path"} {
+		if p.Has(key) && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+
+	return keys
 }
 
 // ParamsLoader defines an interface for loading context and messaging parameters.
@@ -0,0 +1,124 @@
+package dagsterpipes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// Exit codes used by Main.
+const (
+	// ExitCodeSuccess indicates that the run succeeded.
+	ExitCodeSuccess = 0
+
+	// ExitCodeError indicates that the RunFunc returned an error.
+	ExitCodeError = 1
+
+	// ExitCodePanic indicates that the RunFunc panicked, matching the exit code of unrecovered panics.
+	ExitCodePanic = 2
+
+	// ExitCodeProtocolError indicates that the communication with Dagster failed.
+	ExitCodeProtocolError = 3
+
+	// ExitCodeInterrupted indicates that the run was interrupted, following the shell convention 128+SIGINT.
+	ExitCodeInterrupted = 130
+)
+
+// ErrInterrupted indicates that the run was interrupted, e.g. by a signal.
+var ErrInterrupted = errors.New("interrupted")
+
+// ProtocolError represents a failure of the communication with Dagster, like
+// opening the context or writing a message.
+type ProtocolError struct {
+	Op  string // The failed operation, e.g. "open", "write" or "close".
+	Err error  // The underlying error.
+}
+
+// Error returns the failed operation and the underlying error.
+func (e *ProtocolError) Error() string {
+	return fmt.Sprintf("dagster pipes %s: %v", e.Op, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ProtocolError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the process exit code for the outcome of a run:
+// ExitCodeSuccess for nil, ExitCodeInterrupted for ErrInterrupted,
+// ExitCodeProtocolError for a *ProtocolError and ExitCodeError otherwise.
+func ExitCode(err error) int {
+	var protocolErr *ProtocolError
+
+	switch {
+	case err == nil:
+		return ExitCodeSuccess
+	case errors.Is(err, ErrInterrupted):
+		return ExitCodeInterrupted
+	case errors.As(err, &protocolErr):
+		return ExitCodeProtocolError
+	default:
+		return ExitCodeError
+	}
+}
+
+// Main is the entrypoint of a Dagster Pipes process. It opens a session, runs fn,
+// closes the session and exits the process with the exit code of the outcome,
+// see ExitCode. Errors are logged using the configured logger.
+//
+//	func main() {
+//		dagsterpipes.Main(func(context *dagsterpipes.Context[map[string]any]) error {
+//			return context.ReportAssetMaterialization(&dagsterpipes.AssetMaterialization{})
+//		})
+//	}
+func Main[T any](fn RunFunc[T], optFns ...func(o *Options[T])) {
+	MainContext(func(_ context.Context, pc *Context[T]) error {
+		return fn(pc)
+	}, optFns...)
+}
+
+// MainContext is like Main, but passes a context to fn that is canceled when the
+// run is interrupted by a signal, see Session.RunContext.
+func MainContext[T any](fn RunContextFunc[T], optFns ...func(o *Options[T])) {
+	osExit(runMain(fn, optFns...))
+}
+
+// runMain runs fn in a new session and returns the exit code.
+func runMain[T any](fn RunContextFunc[T], optFns ...func(o *Options[T])) int {
+	session, err := New(optFns...)
+	if err != nil {
+		err = &ProtocolError{Op: "open", Err: err}
+		optionsLogger(optFns...).Error(err.Error())
+
+		return ExitCode(err)
+	}
+
+	err = session.RunContext(context.Background(), fn)
+
+	if closeErr := session.Close(); closeErr != nil {
+		err = errors.Join(err, &ProtocolError{Op: "close", Err: closeErr})
+	}
+
+	if err != nil {
+		session.context.logger.Error(fmt.Sprintf("dagster pipes run failed: %v", err))
+	}
+
+	return ExitCode(err)
+}
+
+// optionsLogger returns the logger configured by optFns, for errors that occur
+// before a context exists. Defaults to slog.Default().
+func optionsLogger[T any](optFns ...func(o *Options[T])) *slog.Logger {
+	opts := Options[T]{Logger: slog.Default()}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	if opts.Logger == nil {
+		return slog.Default()
+	}
+
+	return opts.Logger
+}
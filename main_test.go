@@ -0,0 +1,105 @@
+package dagsterpipes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{nil, ExitCodeSuccess},
+		{errors.New("failed"), ExitCodeError},
+		{fmt.Errorf("write: %w", &ProtocolError{Op: "write", Err: io.ErrClosedPipe}), ExitCodeProtocolError},
+		{fmt.Errorf("stopped: %w", ErrInterrupted), ExitCodeInterrupted},
+		{errors.Join(ErrInterrupted, &ProtocolError{Op: "close", Err: io.ErrClosedPipe}), ExitCodeInterrupted},
+	}
+
+	for _, tt := range tests {
+		if actual := ExitCode(tt.err); actual != tt.expected {
+			t.Errorf("Expected exit code %d for %v, got %d", tt.expected, tt.err, actual)
+		}
+	}
+}
+
+func TestMainEntrypoint(t *testing.T) {
+	run := func(t *testing.T, fn RunFunc[map[string]any], optFns ...func(o *Options[map[string]any])) (int, *testMessageChannel) {
+		t.Helper()
+
+		codes := stubOSExit(t)
+		channel := &testMessageChannel{}
+
+		fns := append([]func(o *Options[map[string]any]){func(o *Options[map[string]any]) {
+			o.ParamsLoader = &testParamsLoader{data: &ContextData[map[string]any]{AssetKeys: []string{"asset"}, RunID: "run"}}
+			o.MessageWriter = &testMessageWriter{channel: channel}
+			o.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+		}}, optFns...)
+
+		Main(fn, fns...)
+
+		if len(*codes) != 1 {
+			t.Fatalf("Expected exactly one exit, got %v", *codes)
+		}
+
+		return (*codes)[0], channel
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		code, channel := run(t, func(c *Context[map[string]any]) error {
+			return c.ReportAssetMaterialization(&AssetMaterialization{})
+		})
+
+		if code != ExitCodeSuccess || !channel.closed {
+			t.Fatalf("Expected success and closed channel, got %d", code)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		code, channel := run(t, func(_ *Context[map[string]any]) error {
+			return errors.New("failed")
+		})
+
+		if code != ExitCodeError {
+			t.Fatalf("Expected exit code %d, got %d", ExitCodeError, code)
+		}
+
+		if exception := closedException(t, channel); exception["message"] != "failed" {
+			t.Fatalf("Expected reported error, got %v", exception)
+		}
+	})
+
+	t.Run("ProtocolError", func(t *testing.T) {
+		code, _ := run(t, func(c *Context[map[string]any]) error {
+			c.messageChannel.(*testMessageChannel).err = io.ErrClosedPipe
+			return c.LogInfo("lost")
+		})
+
+		if code != ExitCodeProtocolError {
+			t.Fatalf("Expected exit code %d, got %d", ExitCodeProtocolError, code)
+		}
+	})
+
+	t.Run("OpenError", func(t *testing.T) {
+		var logs bytes.Buffer
+
+		code, _ := run(t, func(_ *Context[map[string]any]) error { return nil }, func(o *Options[map[string]any]) {
+			o.ParamsLoader = &EnvVarParamsLoader[map[string]any]{}
+			o.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+		})
+
+		if code != ExitCodeProtocolError {
+			t.Fatalf("Expected exit code %d, got %d", ExitCodeProtocolError, code)
+		}
+
+		if !strings.Contains(logs.String(), "dagster pipes open") {
+			t.Fatalf("Expected open error in configured logger, got %q", logs.String())
+		}
+	})
+}
@@ -0,0 +1,213 @@
+package dagsterpipes
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// MetadataMarshaler is implemented by types that convert themselves into a metadata value.
+// It takes precedence over type inference and the type option of struct tags.
+type MetadataMarshaler interface {
+	MarshalMetadata() (MetadataValue, error)
+}
+
+// MetadataFromStruct converts the tagged fields of a struct into a metadata map
+// suitable for AssetMaterialization.Metadata or AssetCheck.Metadata.
+//
+// Fields are included if they have a "dagster" tag:
+//
+//	RowCount int       `dagster:"row_count,type=int"`
+//	Report   string    `dagster:"report,type=url"`
+//	Owner    string    `dagster:"owner,omitempty"`
+//	Stats    Stats     `dagster:"stats"`   // nested struct, keys are prefixed with "stats."
+//	Raw      Stats     `dagster:"raw,type=json"`
+//	Internal string    `dagster:"-"`
+//
+// The name defaults to the field name. The type option selects the metadata type,
+// otherwise it is inferred using InferMetadataValue. Fields with omitempty are
+// skipped if they hold a zero value. Nested structs without a type option are
+// flattened using their name as key prefix, embedded structs without a tag are
+// flattened without prefix.
+func MetadataFromStruct(v any) (map[string]any, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, errors.New("metadata from struct: nil pointer")
+		}
+
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("metadata from struct: expected a struct, got %T", v)
+	}
+
+	metadata := make(map[string]any)
+	if err := marshalStructMetadata(rv, "", metadata); err != nil {
+		return nil, err
+	}
+
+	return metadata, nil
+}
+
+// metadataTag represents a parsed "dagster" struct tag.
+type metadataTag struct {
+	name         string // The metadata key.
+	metadataType string // The explicit metadata type, if any.
+	omitEmpty    bool   // Whether zero values are skipped.
+}
+
+// parseMetadataTag parses a "dagster" struct tag.
+func parseMetadataTag(tag string) (metadataTag, error) {
+	parts := strings.Split(tag, ",")
+	parsed := metadataTag{name: parts[0]}
+
+	for _, option := range parts[1:] {
+		switch {
+		case option == "omitempty":
+			parsed.omitEmpty = true
+		case strings.HasPrefix(option, "type="):
+			parsed.metadataType = strings.TrimPrefix(option, "type=")
+		case option == "":
+		default:
+			return metadataTag{}, fmt.Errorf("unknown dagster tag option %q", option)
+		}
+	}
+
+	return parsed, nil
+}
+
+// marshalStructMetadata adds the tagged fields of rv to metadata, prefixing their keys.
+func marshalStructMetadata(rv reflect.Value, prefix string, metadata map[string]any) error {
+	rt := rv.Type()
+
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+		value := rv.Field(i)
+
+		tag, tagged := field.Tag.Lookup("dagster")
+
+		// Untagged embedded structs of unexported types still promote their exported
+		// fields. Other unexported fields are skipped, their values cannot be read.
+		if !field.IsExported() && (tagged || !field.Anonymous || !isFlattenable(value)) {
+			continue
+		}
+		if !tagged {
+			if field.Anonymous && isFlattenable(value) {
+				if err := marshalNestedMetadata(value, prefix, metadata); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		if tag == "-" {
+			continue
+		}
+
+		parsed, err := parseMetadataTag(tag)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		name := parsed.name
+		if name == "" {
+			name = field.Name
+		}
+
+		key := prefix + name
+
+		if parsed.omitEmpty && value.IsZero() {
+			continue
+		}
+
+		if parsed.metadataType == "" && isFlattenable(value) {
+			if err := marshalNestedMetadata(value, key+".", metadata); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		metadataValue, err := marshalFieldMetadata(value, parsed.metadataType)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		if _, exists := metadata[key]; exists {
+			return fmt.Errorf("field %s: duplicate metadata key %q", field.Name, key)
+		}
+
+		metadata[key] = metadataValue
+	}
+
+	return nil
+}
+
+// marshalNestedMetadata flattens a nested struct or struct pointer into metadata.
+func marshalNestedMetadata(value reflect.Value, prefix string, metadata map[string]any) error {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+
+		value = value.Elem()
+	}
+
+	return marshalStructMetadata(value, prefix, metadata)
+}
+
+// marshalFieldMetadata converts a field value into a metadata value of the given type,
+// inferring the type if it is empty.
+func marshalFieldMetadata(value reflect.Value, metadataType string) (MetadataValue, error) {
+	raw := value.Interface()
+
+	if _, ok := raw.(MetadataMarshaler); ok || metadataType == "" {
+		return InferMetadataValue(raw)
+	}
+
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return NullMetadata(), nil
+		}
+
+		value = value.Elem()
+		raw = value.Interface()
+	}
+
+	return NewMetadataValue(metadataType, raw)
+}
+
+// isFlattenable reports whether a value is a struct, or pointer to a struct,
+// that is flattened instead of being converted into a single metadata value.
+func isFlattenable(value reflect.Value) bool {
+	t := value.Type()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	switch t {
+	case reflect.TypeFor[time.Time](), reflect.TypeFor[url.URL](),
+		reflect.TypeFor[Table](), reflect.TypeFor[TableSchema](), reflect.TypeFor[TableColumnLineage]():
+		return false
+	}
+
+	if t.Implements(reflect.TypeFor[MetadataMarshaler]()) || reflect.PointerTo(t).Implements(reflect.TypeFor[MetadataMarshaler]()) {
+		return false
+	}
+
+	if _, ok := metadataInferences.Load(t); ok {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,124 @@
+package dagsterpipes
+
+import (
+	"testing"
+	"time"
+)
+
+type testQuality float64
+
+func (q testQuality) MarshalMetadata() (MetadataValue, error) {
+	return MarkdownMetadata("**quality**: " + time.Duration(q).String()), nil
+}
+
+type testStats struct {
+	Min int `dagster:"min"`
+	Max int `dagster:"max"`
+}
+
+type testEmbedded struct {
+	Source string `dagster:"source"`
+}
+
+type testResult struct {
+	testEmbedded
+	RowCount  int         `dagster:"row_count,type=int"`
+	Report    string      `dagster:"report,type=url"`
+	Owner     string      `dagster:"owner,omitempty"`
+	UpdatedAt time.Time   `dagster:"updated_at"`
+	Stats     testStats   `dagster:"stats"`
+	RawStats  *testStats  `dagster:"raw_stats,type=json"`
+	Quality   testQuality `dagster:"quality"`
+	Internal  string      `dagster:"-"`
+	Untagged  string
+}
+
+func TestMetadataFromStruct(t *testing.T) {
+	t.Run("Tags", func(t *testing.T) {
+		result := testResult{
+			testEmbedded: testEmbedded{Source: "db"},
+			RowCount:     10,
+			Report:       "https://example.com/report",
+			UpdatedAt:    time.Unix(1700000000, 0),
+			Stats:        testStats{Min: 1, Max: 2},
+			RawStats:     &testStats{Min: 3, Max: 4},
+			Quality:      testQuality(time.Second),
+			Internal:     "internal",
+			Untagged:     "untagged",
+		}
+
+		metadata, err := MetadataFromStruct(&result)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		want := map[string]string{
+			"source":     MetadataTypeText,
+			"row_count":  MetadataTypeInt,
+			"report":     MetadataTypeURL,
+			"updated_at": MetadataTypeTimestamp,
+			"stats.min":  MetadataTypeInt,
+			"stats.max":  MetadataTypeInt,
+			"raw_stats":  MetadataTypeJSON,
+			"quality":    MetadataTypeMarkdown,
+		}
+
+		if len(metadata) != len(want) {
+			t.Fatalf("Expected %d entries, got %v", len(want), metadata)
+		}
+
+		for key, typ := range want {
+			value, ok := metadata[key].(MetadataValue)
+			if !ok {
+				t.Fatalf("Expected metadata value for %q, got %v", key, metadata[key])
+			}
+
+			if value.Type != typ {
+				t.Fatalf("Expected type %q for %q, got %q", typ, key, value.Type)
+			}
+		}
+
+		check := &AssetCheck{CheckName: "check", Passed: true, Metadata: metadata}
+		if err := validateMetadata(check.Metadata); err != nil {
+			t.Fatalf("Unexpected validation error: %v", err)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		if _, err := MetadataFromStruct(42); err == nil {
+			t.Fatal("Expected error for non-struct value")
+		}
+
+		invalidType := struct {
+			Count string `dagster:"count,type=int"`
+		}{Count: "many"}
+
+		if _, err := MetadataFromStruct(invalidType); err == nil {
+			t.Fatal("Expected error for invalid type")
+		}
+
+		invalidOption := struct {
+			Count int `dagster:"count,unknown"`
+		}{}
+
+		if _, err := MetadataFromStruct(invalidOption); err == nil {
+			t.Fatal("Expected error for unknown tag option")
+		}
+	})
+
+	t.Run("UnexportedEmbedded", func(t *testing.T) {
+		tagged := struct {
+			testEmbedded `dagster:"embedded,type=json"`
+			RowCount     int `dagster:"row_count"`
+		}{testEmbedded: testEmbedded{Source: "db"}, RowCount: 1}
+
+		metadata, err := MetadataFromStruct(tagged)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if _, ok := metadata["embedded"]; ok || len(metadata) != 1 {
+			t.Fatalf("Expected tagged unexported embedded struct to be skipped, got %v", metadata)
+		}
+	})
+}
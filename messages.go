@@ -1,10 +1,8 @@
 package dagsterpipes
 
-import "encoding/json"
-
-// Constants for metadata type inference.
-const (
-	MetadataTypeInfer = "__infer__"
+import (
+	"encoding/json"
+	"fmt"
 )
 
 // Method represents different types of communication methods.
@@ -43,10 +41,12 @@ type Closed struct {
 // Log represents the parameters for the "log" method.
 type Log struct {
 	Message string `json:"message"` // The log message.
-	Level   string `json:"level"`   // The log level (e.g., DEBUG, INFO).
+	Level   string `json:"level"`   // The Dagster log level (DEBUG, INFO, WARNING, ERROR or CRITICAL).
 }
 
 // MetadataValue represents a metadata entry with a type and raw value.
+// Use the typed constructors such as TextMetadata or URLMetadata to create values
+// with the type tags expected by Dagster.
 type MetadataValue struct {
 	RawValue any    `json:"raw_value"` // The raw value of the metadata.
 	Type     string `json:"type"`      // The type of the metadata.
@@ -57,10 +57,16 @@ type AssetMaterialization struct {
 	AssetKey    string         // The unique key of the asset being materialized.
 	DataVersion string         // The version of the asset data.
 	Metadata    map[string]any // Metadata associated with the asset materialization.
+	errs        []error        // Errors recorded by the With* metadata setters.
 }
 
 // MarshalJSON serializes AssetMaterialization into JSON, normalizing metadata.
 func (a AssetMaterialization) MarshalJSON() ([]byte, error) {
+	metadata, err := normalizeParamMetadata(a.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
 	normalized := struct {
 		AssetKey    string                   `json:"asset_key"`
 		DataVersion string                   `json:"data_version"`
@@ -68,7 +74,7 @@ func (a AssetMaterialization) MarshalJSON() ([]byte, error) {
 	}{
 		AssetKey:    a.AssetKey,
 		DataVersion: a.DataVersion,
-		Metadata:    normalizeParamMetadata(a.Metadata),
+		Metadata:    metadata,
 	}
 
 	return json.Marshal(normalized)
@@ -90,12 +96,17 @@ type AssetCheck struct {
 	AssetKey  string             // The key of the asset being checked.
 	CheckName string             // The name of the check being performed.
 	Passed    bool               // Whether the check passed or failed.
-	Serverity AssetCheckSeverity // The severity of the check result.
+	Serverity AssetCheckSeverity // The severity of the check result. Defaults to AssetCheckSeverityError.
 	Metadata  map[string]any     // Metadata associated with the asset check.
 }
 
 // MarshalJSON serializes AssetCheck into JSON, normalizing metadata.
 func (a AssetCheck) MarshalJSON() ([]byte, error) {
+	metadata, err := normalizeParamMetadata(a.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
 	normalized := struct {
 		AssetKey  string                   `json:"asset_key"`
 		CheckName string                   `json:"check_name"`
@@ -107,7 +118,7 @@ func (a AssetCheck) MarshalJSON() ([]byte, error) {
 		CheckName: a.CheckName,
 		Passed:    a.Passed,
 		Serverity: a.Serverity,
-		Metadata:  normalizeParamMetadata(a.Metadata),
+		Metadata:  metadata,
 	}
 
 	return json.Marshal(normalized)
@@ -126,22 +137,20 @@ type Message struct {
 }
 
 // normalizeParamMetadata validates and normalizes the metadata parameter.
+// Values that are not a MetadataValue are converted using InferMetadataValue.
 func normalizeParamMetadata(
 	metadata map[string]any,
-) map[string]MetadataValue {
+) (map[string]MetadataValue, error) {
 	newMetadata := make(map[string]MetadataValue)
 
 	for key, value := range metadata {
-		switch v := value.(type) {
-		case MetadataValue:
-			newMetadata[key] = v
-		default:
-			newMetadata[key] = MetadataValue{
-				RawValue: v,
-				Type:     MetadataTypeInfer,
-			}
+		v, err := InferMetadataValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("metadata %q: %w", key, err)
 		}
+
+		newMetadata[key] = v
 	}
 
-	return newMetadata
+	return newMetadata, nil
 }
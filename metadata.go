@@ -0,0 +1,315 @@
+package dagsterpipes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"time"
+)
+
+// Metadata types understood by the Dagster Pipes message reader.
+const (
+	MetadataTypeInfer      = "__infer__"
+	MetadataTypeText       = "text"
+	MetadataTypeURL        = "url"
+	MetadataTypePath       = "path"
+	MetadataTypeNotebook   = "notebook"
+	MetadataTypeJSON       = "json"
+	MetadataTypeMarkdown   = "md"
+	MetadataTypeFloat      = "float"
+	MetadataTypeInt        = "int"
+	MetadataTypeBool       = "bool"
+	MetadataTypeDagsterRun = "dagster_run"
+	MetadataTypeAsset      = "asset"
+	MetadataTypeNull       = "null"
+	MetadataTypeTimestamp  = "timestamp"
+
+	MetadataTypeTable              = "table"
+	MetadataTypeTableSchema        = "table_schema"
+	MetadataTypeTableColumnLineage = "table_column_lineage"
+)
+
+// integer is the set of integer types accepted by IntMetadata.
+type integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// float is the set of floating-point types accepted by FloatMetadata.
+type float interface {
+	~float32 | ~float64
+}
+
+// TextMetadata creates a text metadata value.
+func TextMetadata(text string) MetadataValue {
+	return MetadataValue{RawValue: text, Type: MetadataTypeText}
+}
+
+// URLMetadata creates a URL metadata value, rendered as a clickable link.
+func URLMetadata(u string) MetadataValue {
+	return MetadataValue{RawValue: u, Type: MetadataTypeURL}
+}
+
+// PathMetadata creates a filesystem path metadata value.
+func PathMetadata(path string) MetadataValue {
+	return MetadataValue{RawValue: path, Type: MetadataTypePath}
+}
+
+// NotebookMetadata creates a metadata value referencing a notebook by its path.
+func NotebookMetadata(path string) MetadataValue {
+	return MetadataValue{RawValue: path, Type: MetadataTypeNotebook}
+}
+
+// JSONMetadata creates a JSON metadata value. The value must serialize to a JSON object or array.
+func JSONMetadata(v any) MetadataValue {
+	return MetadataValue{RawValue: v, Type: MetadataTypeJSON}
+}
+
+// MarkdownMetadata creates a Markdown metadata value.
+func MarkdownMetadata(markdown string) MetadataValue {
+	return MetadataValue{RawValue: markdown, Type: MetadataTypeMarkdown}
+}
+
+// FloatMetadata creates a float metadata value. The value must be finite.
+func FloatMetadata[F float](f F) MetadataValue {
+	return MetadataValue{RawValue: float64(f), Type: MetadataTypeFloat}
+}
+
+// IntMetadata creates an integer metadata value.
+func IntMetadata[I integer](i I) MetadataValue {
+	return MetadataValue{RawValue: i, Type: MetadataTypeInt}
+}
+
+// BoolMetadata creates a boolean metadata value.
+func BoolMetadata(b bool) MetadataValue {
+	return MetadataValue{RawValue: b, Type: MetadataTypeBool}
+}
+
+// TimestampMetadata creates a timestamp metadata value, serialized as seconds since the Unix epoch.
+func TimestampMetadata(t time.Time) MetadataValue {
+	return MetadataValue{RawValue: float64(t.UnixNano()) / float64(time.Second), Type: MetadataTypeTimestamp}
+}
+
+// NullMetadata creates a null metadata value.
+func NullMetadata() MetadataValue {
+	return MetadataValue{RawValue: nil, Type: MetadataTypeNull}
+}
+
+// AssetMetadata creates a metadata value referencing an asset by its key, e.g. "prefix/name".
+func AssetMetadata(assetKey string) MetadataValue {
+	return MetadataValue{RawValue: assetKey, Type: MetadataTypeAsset}
+}
+
+// DagsterRunMetadata creates a metadata value referencing a Dagster run by its ID.
+func DagsterRunMetadata(runID string) MetadataValue {
+	return MetadataValue{RawValue: runID, Type: MetadataTypeDagsterRun}
+}
+
+// NewMetadataValue creates a metadata value of the given type, converting common
+// Go representations of the raw value, e.g. a time.Time for a timestamp or a
+// *url.URL for a URL. Returns an error if the raw value is invalid for the type.
+func NewMetadataValue(metadataType string, raw any) (MetadataValue, error) {
+	value := MetadataValue{RawValue: raw, Type: metadataType}
+
+	switch metadataType {
+	case MetadataTypeTimestamp:
+		if t, ok := raw.(time.Time); ok {
+			value = TimestampMetadata(t)
+		}
+	case MetadataTypeURL:
+		if u, ok := raw.(*url.URL); ok && u != nil {
+			value.RawValue = u.String()
+		}
+	case MetadataTypeText, MetadataTypeMarkdown:
+		if s, ok := raw.(fmt.Stringer); ok {
+			value.RawValue = s.String()
+		}
+	case MetadataTypeFloat:
+		if f, ok := toFloat64(raw); ok {
+			value.RawValue = f
+		}
+	}
+
+	if err := value.Validate(); err != nil {
+		return MetadataValue{}, err
+	}
+
+	return value, nil
+}
+
+// Validate checks that the raw value is valid for the metadata type.
+func (m MetadataValue) Validate() error {
+	validate, ok := metadataValidators[m.Type]
+	if !ok {
+		return fmt.Errorf("unknown metadata type %q", m.Type)
+	}
+
+	if err := validate(m.RawValue); err != nil {
+		return fmt.Errorf("invalid %s metadata: %w", m.Type, err)
+	}
+
+	return nil
+}
+
+// metadataValidators maps the metadata types to the validation of their raw values.
+var metadataValidators = map[string]func(raw any) error{
+	MetadataTypeInfer:      validateJSONMarshalable,
+	MetadataTypeText:       validateString,
+	MetadataTypeURL:        validateURL,
+	MetadataTypePath:       validateString,
+	MetadataTypeNotebook:   validateString,
+	MetadataTypeJSON:       validateJSONContainer,
+	MetadataTypeMarkdown:   validateString,
+	MetadataTypeFloat:      validateFinite,
+	MetadataTypeInt:        validateInteger,
+	MetadataTypeBool:       validateBool,
+	MetadataTypeDagsterRun: validateNonEmptyString,
+	MetadataTypeAsset:      validateNonEmptyString,
+	MetadataTypeNull:       validateNull,
+	MetadataTypeTimestamp:  validateFinite,
+
+	MetadataTypeTable:              validateTable,
+	MetadataTypeTableSchema:        validateTableSchema,
+	MetadataTypeTableColumnLineage: validateTableColumnLineage,
+}
+
+// validateMetadata normalizes and validates all values of a metadata map.
+func validateMetadata(metadata map[string]any) error {
+	normalized, err := normalizeParamMetadata(metadata)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range normalized {
+		if err := value.Validate(); err != nil {
+			return fmt.Errorf("metadata %q: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+func validateString(raw any) error {
+	if _, ok := raw.(string); !ok {
+		return fmt.Errorf("expected a string, got %T", raw)
+	}
+
+	return nil
+}
+
+func validateNonEmptyString(raw any) error {
+	if s, ok := raw.(string); !ok || s == "" {
+		return fmt.Errorf("expected a non-empty string, got %#v", raw)
+	}
+
+	return nil
+}
+
+func validateURL(raw any) error {
+	s, ok := raw.(string)
+	if !ok {
+		return fmt.Errorf("expected a string, got %T", raw)
+	}
+
+	if _, err := url.Parse(s); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func validateBool(raw any) error {
+	if _, ok := raw.(bool); !ok {
+		return fmt.Errorf("expected a bool, got %T", raw)
+	}
+
+	return nil
+}
+
+func validateNull(raw any) error {
+	if raw != nil {
+		return fmt.Errorf("expected nil, got %T", raw)
+	}
+
+	return nil
+}
+
+func validateFinite(raw any) error {
+	f, ok := toFloat64(raw)
+	if !ok {
+		return fmt.Errorf("expected a number, got %T", raw)
+	}
+
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("expected a finite number, got %v", f)
+	}
+
+	return nil
+}
+
+func validateInteger(raw any) error {
+	switch v := raw.(type) {
+	case json.Number:
+		if _, err := v.Int64(); err != nil {
+			return fmt.Errorf("expected an integer, got %s", v)
+		}
+
+		return nil
+	case float32, float64:
+		f, _ := toFloat64(v)
+		if f != math.Trunc(f) || math.IsInf(f, 0) {
+			return fmt.Errorf("expected an integer, got %v", f)
+		}
+
+		return nil
+	}
+
+	switch reflect.ValueOf(raw).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return nil
+	default:
+		return fmt.Errorf("expected an integer, got %T", raw)
+	}
+}
+
+func validateJSONMarshalable(raw any) error {
+	_, err := json.Marshal(raw)
+	return err
+}
+
+func validateJSONContainer(raw any) error {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+
+	if len(data) == 0 || (data[0] != '{' && data[0] != '[') {
+		return errors.New("expected a value serializing to a JSON object or array")
+	}
+
+	return nil
+}
+
+// toFloat64 converts numeric values to float64.
+func toFloat64(raw any) (float64, bool) {
+	if n, ok := raw.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+
+	v := reflect.ValueOf(raw)
+
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,218 @@
+package dagsterpipes
+
+import (
+	"math"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMetadataValue(t *testing.T) {
+	t.Run("Constructors", func(t *testing.T) {
+		ts := time.Date(2024, 1, 2, 3, 4, 5, 500_000_000, time.UTC)
+
+		tests := []struct {
+			name  string
+			value MetadataValue
+			typ   string
+			raw   any
+		}{
+			{"Text", TextMetadata("text"), "text", "text"},
+			{"URL", URLMetadata("https://example.com"), "url", "https://example.com"},
+			{"Path", PathMetadata("/tmp/x"), "path", "/tmp/x"},
+			{"Notebook", NotebookMetadata("nb.ipynb"), "notebook", "nb.ipynb"},
+			{"JSON", JSONMetadata(map[string]any{"a": 1}), "json", map[string]any{"a": 1}},
+			{"Markdown", MarkdownMetadata("# md"), "md", "# md"},
+			{"Float", FloatMetadata(float32(1.5)), "float", 1.5},
+			{"Int", IntMetadata(int64(42)), "int", int64(42)},
+			{"Bool", BoolMetadata(true), "bool", true},
+			{"Timestamp", TimestampMetadata(ts), "timestamp", float64(ts.Unix()) + 0.5},
+			{"Null", NullMetadata(), "null", nil},
+			{"Asset", AssetMetadata("prefix/asset"), "asset", "prefix/asset"},
+			{"DagsterRun", DagsterRunMetadata("run-id"), "dagster_run", "run-id"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if tt.value.Type != tt.typ {
+					t.Fatalf("Expected type %q, got %q", tt.typ, tt.value.Type)
+				}
+
+				if err := tt.value.Validate(); err != nil {
+					t.Fatalf("Unexpected validation error: %v", err)
+				}
+
+				if _, ok := tt.raw.(map[string]any); !ok && tt.value.RawValue != tt.raw {
+					t.Fatalf("Expected raw value %#v, got %#v", tt.raw, tt.value.RawValue)
+				}
+			})
+		}
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		invalid := []MetadataValue{
+			FloatMetadata(math.NaN()),
+			FloatMetadata(math.Inf(1)),
+			JSONMetadata("not a container"),
+			AssetMetadata(""),
+			{RawValue: "1", Type: MetadataTypeInt},
+			{RawValue: 1.5, Type: MetadataTypeInt},
+			{RawValue: "x", Type: "unknown"},
+		}
+
+		for _, value := range invalid {
+			if err := value.Validate(); err == nil {
+				t.Fatalf("Expected validation error for %#v", value)
+			}
+		}
+	})
+
+	t.Run("NewMetadataValue", func(t *testing.T) {
+		u, _ := url.Parse("https://example.com/report")
+
+		value, err := NewMetadataValue(MetadataTypeURL, u)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if value.RawValue != "https://example.com/report" {
+			t.Fatalf("Expected URL string, got %#v", value.RawValue)
+		}
+
+		if _, err := NewMetadataValue(MetadataTypeBool, "yes"); err == nil {
+			t.Fatal("Expected error for invalid bool")
+		}
+	})
+
+	t.Run("InferMetadataValue", func(t *testing.T) {
+		u, _ := url.Parse("https://example.com")
+		ts := time.Unix(1700000000, 0)
+
+		type level int
+
+		tests := []struct {
+			name  string
+			value any
+			typ   string
+			raw   any
+		}{
+			{"Nil", nil, "null", nil},
+			{"String", "bar", "text", "bar"},
+			{"Int", 42, "int", int64(42)},
+			{"Uint", uint8(7), "int", uint64(7)},
+			{"Float", 1.5, "float", 1.5},
+			{"NaN", math.NaN(), "text", "NaN"},
+			{"Inf", math.Inf(-1), "text", "-Inf"},
+			{"Bool", true, "bool", true},
+			{"Time", ts, "timestamp", float64(1700000000)},
+			{"URL", u, "url", "https://example.com"},
+			{"Stringer", time.Second, "text", "1s"},
+			{"Pointer", &ts, "timestamp", float64(1700000000)},
+			{"Named", level(3), "int", int64(3)},
+			{"Struct", struct{ A int }{1}, "json", nil},
+			{"Map", map[string]int{"a": 1}, "json", nil},
+			{"NilMap", map[string]int(nil), "null", nil},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				value, err := InferMetadataValue(tt.value)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				if value.Type != tt.typ {
+					t.Fatalf("Expected type %q, got %q", tt.typ, value.Type)
+				}
+
+				if tt.raw != nil && value.RawValue != tt.raw {
+					t.Fatalf("Expected raw value %#v, got %#v", tt.raw, value.RawValue)
+				}
+
+				if err := value.Validate(); err != nil {
+					t.Fatalf("Unexpected validation error: %v", err)
+				}
+			})
+		}
+
+		if _, err := InferMetadataValue(func() {}); err == nil {
+			t.Fatal("Expected error for func value")
+		}
+	})
+
+	t.Run("RegisterMetadataInference", func(t *testing.T) {
+		type report struct{ Link string }
+
+		RegisterMetadataInference(func(r report) MetadataValue { return URLMetadata(r.Link) })
+		defer metadataInferences.Delete(reflect.TypeFor[report]())
+
+		value, err := InferMetadataValue(report{Link: "https://example.com"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if value.Type != MetadataTypeURL {
+			t.Fatalf("Expected url type, got %q", value.Type)
+		}
+	})
+
+	t.Run("ReportAssetMaterialization", func(t *testing.T) {
+		pc, _ := newTestContext(t, []string{"asset"})
+
+		err := pc.ReportAssetMaterialization(&AssetMaterialization{
+			Metadata: map[string]any{"ratio": FloatMetadata(math.NaN())},
+		})
+		if err == nil {
+			t.Fatal("Expected error for invalid metadata")
+		}
+	})
+}
+
+func TestAssetMaterializationMetadataKeys(t *testing.T) {
+	t.Run("Setters", func(t *testing.T) {
+		schema := TableSchema{Columns: []TableColumn{{Name: "id", Type: "int"}}}
+
+		materialization := (&AssetMaterialization{}).
+			WithRowCount(10).
+			WithColumnSchema(schema).
+			WithRelationIdentifier("db.schema.table").
+			WithTableName("table").
+			WithURI("s3://bucket/table")
+
+		if err := materialization.Err(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		want := map[string]string{
+			MetadataKeyRowCount:           MetadataTypeInt,
+			MetadataKeyColumnSchema:       MetadataTypeTableSchema,
+			MetadataKeyRelationIdentifier: MetadataTypeText,
+			MetadataKeyTableName:          MetadataTypeText,
+			MetadataKeyURI:                MetadataTypeText,
+		}
+
+		for key, typ := range want {
+			if value, _ := materialization.Metadata[key].(MetadataValue); value.Type != typ {
+				t.Fatalf("Expected type %q for %q, got %v", typ, key, materialization.Metadata[key])
+			}
+		}
+	})
+
+	t.Run("Duplicates", func(t *testing.T) {
+		materialization := &AssetMaterialization{Metadata: map[string]any{MetadataKeyRowCount: 10}}
+
+		if err := materialization.WithRowCount(10).Err(); err != nil {
+			t.Fatalf("Expected identical value to be accepted, got %v", err)
+		}
+
+		if err := materialization.WithRowCount(11).Err(); err == nil {
+			t.Fatal("Expected error for conflicting value")
+		}
+
+		pc, _ := newTestContext(t, []string{"asset"})
+		if err := pc.ReportAssetMaterialization(materialization); err == nil {
+			t.Fatal("Expected report to fail for conflicting metadata")
+		}
+	})
+}
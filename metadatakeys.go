@@ -0,0 +1,96 @@
+package dagsterpipes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Metadata keys with a special meaning in Dagster.
+const (
+	// MetadataKeyRowCount holds the number of rows of a table asset.
+	MetadataKeyRowCount = "dagster/row_count"
+
+	// MetadataKeyColumnSchema holds the column schema of a table asset.
+	MetadataKeyColumnSchema = "dagster/column_schema"
+
+	// MetadataKeyColumnLineage holds the column lineage of a table asset.
+	MetadataKeyColumnLineage = "dagster/column_lineage"
+
+	// MetadataKeyRelationIdentifier holds the identifier of the table in its storage, e.g. "db.schema.table".
+	MetadataKeyRelationIdentifier = "dagster/relation_identifier"
+
+	// MetadataKeyTableName holds the name of the table.
+	MetadataKeyTableName = "dagster/table_name"
+
+	// MetadataKeyURI holds the URI of the asset's storage location.
+	MetadataKeyURI = "dagster/uri"
+)
+
+// WithRowCount sets the row count of the materialized table.
+func (a *AssetMaterialization) WithRowCount(rowCount int64) *AssetMaterialization {
+	return a.withMetadata(MetadataKeyRowCount, IntMetadata(rowCount))
+}
+
+// WithColumnSchema sets the column schema of the materialized table.
+func (a *AssetMaterialization) WithColumnSchema(schema TableSchema) *AssetMaterialization {
+	return a.withMetadata(MetadataKeyColumnSchema, TableSchemaMetadata(schema))
+}
+
+// WithColumnLineage sets the column lineage of the materialized table.
+func (a *AssetMaterialization) WithColumnLineage(lineage TableColumnLineage) *AssetMaterialization {
+	return a.withMetadata(MetadataKeyColumnLineage, TableColumnLineageMetadata(lineage))
+}
+
+// WithRelationIdentifier sets the identifier of the materialized table in its storage.
+func (a *AssetMaterialization) WithRelationIdentifier(identifier string) *AssetMaterialization {
+	return a.withMetadata(MetadataKeyRelationIdentifier, TextMetadata(identifier))
+}
+
+// WithTableName sets the name of the materialized table.
+func (a *AssetMaterialization) WithTableName(name string) *AssetMaterialization {
+	return a.withMetadata(MetadataKeyTableName, TextMetadata(name))
+}
+
+// WithURI sets the URI of the storage location of the materialized asset.
+func (a *AssetMaterialization) WithURI(uri string) *AssetMaterialization {
+	return a.withMetadata(MetadataKeyURI, TextMetadata(uri))
+}
+
+// Err returns the errors recorded by the With* setters, e.g. conflicting values for the same key.
+func (a *AssetMaterialization) Err() error {
+	return errors.Join(a.errs...)
+}
+
+// withMetadata sets a metadata entry, recording an error if the key already holds a different value.
+func (a *AssetMaterialization) withMetadata(key string, value MetadataValue) *AssetMaterialization {
+	if a.Metadata == nil {
+		a.Metadata = make(map[string]any)
+	}
+
+	if existing, exists := a.Metadata[key]; exists {
+		if !sameMetadataValue(existing, value) {
+			a.errs = append(a.errs, fmt.Errorf("conflicting values for metadata %q", key))
+		}
+
+		return a
+	}
+
+	a.Metadata[key] = value
+
+	return a
+}
+
+// sameMetadataValue reports whether an existing metadata entry serializes like the given value.
+func sameMetadataValue(existing any, value MetadataValue) bool {
+	normalized, err := InferMetadataValue(existing)
+	if err != nil {
+		return false
+	}
+
+	a, errA := json.Marshal(normalized)
+	b, errB := json.Marshal(value)
+
+	return errA == nil && errB == nil && bytes.Equal(a, b)
+}
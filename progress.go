@@ -0,0 +1,164 @@
+package dagsterpipes
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// DefaultProgressInterval is the default minimum interval between progress messages.
+const DefaultProgressInterval = 5 * time.Second
+
+// ProgressMode selects how progress and heartbeats are reported.
+type ProgressMode int
+
+const (
+	// ProgressModeLog reports progress as "log" messages, shown in the run logs.
+	ProgressModeLog ProgressMode = iota
+
+	// ProgressModeCustom reports progress as typed custom messages with a Progress payload.
+	ProgressModeCustom
+)
+
+// ProgressOptions defines configuration options for progress reporting and heartbeats.
+type ProgressOptions struct {
+	Mode              ProgressMode  // How progress is reported. Defaults to ProgressModeLog.
+	Interval          time.Duration // Minimum interval between progress messages. Defaults to DefaultProgressInterval if not positive.
+	HeartbeatInterval time.Duration // Interval between heartbeats. Heartbeats are disabled if not positive.
+}
+
+// Progress is the payload of progress and heartbeat custom messages.
+type Progress struct {
+	Done           int64   `json:"done"`              // Number of completed work items.
+	Total          int64   `json:"total"`             // Total number of work items, or 0 if unknown.
+	Message        string  `json:"message,omitempty"` // Optional description of the current step.
+	Heartbeat      bool    `json:"heartbeat"`         // Whether the message is a heartbeat.
+	ElapsedSeconds float64 `json:"elapsed_seconds"`   // Seconds since the context was opened.
+}
+
+// PayloadType returns the type tag of progress custom messages.
+func (Progress) PayloadType() string {
+	return "progress"
+}
+
+// String formats the progress for log messages.
+func (p Progress) String() string {
+	var s string
+
+	switch {
+	case p.Heartbeat:
+		s = fmt.Sprintf("heartbeat: running for %s", time.Duration(p.ElapsedSeconds*float64(time.Second)).Round(time.Second))
+		if p.Done > 0 || p.Total > 0 {
+			s += fmt.Sprintf(", progress %s", formatProgressCount(p.Done, p.Total))
+		}
+	default:
+		s = "progress " + formatProgressCount(p.Done, p.Total)
+	}
+
+	if p.Message != "" {
+		s += ": " + p.Message
+	}
+
+	return s
+}
+
+// formatProgressCount formats completed and total work items, e.g. "40/100 (40.0%)".
+func formatProgressCount(done, total int64) string {
+	if total <= 0 {
+		return fmt.Sprintf("%d", done)
+	}
+
+	return fmt.Sprintf("%d/%d (%.1f%%)", done, total, float64(done)*100/float64(total))
+}
+
+// ReportProgress reports the progress of the run. Messages are rate limited to
+// one per ProgressOptions.Interval, except for the completion (done >= total),
+// which is always reported. Skipped updates are not an error.
+func (c *Context[T]) ReportProgress(done, total int64, message string) error {
+	return c.ReportProgressContext(context.Background(), done, total, message)
+}
+
+// ReportProgressContext is like ReportProgress, but gives up writing the message
+// when ctx is canceled or its deadline is exceeded.
+func (c *Context[T]) ReportProgressContext(ctx context.Context, done, total int64, message string) error {
+	now := time.Now()
+	complete := total > 0 && done >= total
+
+	c.mu.Lock()
+	if !complete && !c.lastProgressAt.IsZero() && now.Sub(c.lastProgressAt) < c.progress.Interval {
+		c.lastProgress = Progress{Done: done, Total: total, Message: message}
+		c.mu.Unlock()
+
+		return nil
+	}
+
+	c.lastProgressAt = now
+	c.lastProgress = Progress{Done: done, Total: total, Message: message}
+	c.mu.Unlock()
+
+	return c.sendProgress(ctx, Progress{
+		Done:           done,
+		Total:          total,
+		Message:        message,
+		ElapsedSeconds: now.Sub(c.openedAt).Seconds(),
+	})
+}
+
+// sendProgress sends a progress or heartbeat message in the configured mode.
+func (c *Context[T]) sendProgress(ctx context.Context, progress Progress) error {
+	if c.progress.Mode == ProgressModeCustom {
+		return ReportCustomContext(ctx, c, progress)
+	}
+
+	return c.log(ctx, slog.LevelInfo, progress.String())
+}
+
+// startHeartbeat starts sending heartbeats in the background until stopHeartbeat is called.
+func (c *Context[T]) startHeartbeat() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c.heartbeatCancel = cancel
+	c.heartbeatDone = make(chan struct{})
+
+	go func() {
+		defer close(c.heartbeatDone)
+
+		ticker := time.NewTicker(c.progress.HeartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				c.mu.RLock()
+				heartbeat := c.lastProgress
+				c.mu.RUnlock()
+
+				heartbeat.Heartbeat = true
+				heartbeat.ElapsedSeconds = now.Sub(c.openedAt).Seconds()
+
+				// Failed heartbeats are not fatal, the next one is sent with the next tick.
+				_ = c.sendProgress(ctx, heartbeat)
+			}
+		}
+	}()
+}
+
+// stopHeartbeat stops the heartbeats, canceling a heartbeat in flight, and waits
+// for them to finish until ctx is done.
+func (c *Context[T]) stopHeartbeat(ctx context.Context) error {
+	if c.heartbeatDone == nil {
+		return nil
+	}
+
+	c.heartbeatCancel()
+
+	select {
+	case <-c.heartbeatDone:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
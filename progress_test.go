@@ -0,0 +1,175 @@
+package dagsterpipes
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stallingChannel is a recording channel whose log writes stall until released.
+type stallingChannel struct {
+	testMessageChannel
+	stalled chan struct{} // Closed when the first write stalls.
+	release chan struct{} // Closed to release stalled writes.
+	once    sync.Once
+}
+
+func (ch *stallingChannel) WriteMessage(message Message) error {
+	if message.Method == MethodLog {
+		ch.once.Do(func() { close(ch.stalled) })
+		<-ch.release
+	}
+
+	return ch.testMessageChannel.WriteMessage(message)
+}
+
+// channelWriter is a MessageWriter handing out a fixed channel.
+type channelWriter struct {
+	channel MessageChannel
+}
+
+func (mw *channelWriter) Open(_ *MessagesParams) (MessageChannel, error) {
+	return mw.channel, nil
+}
+
+func (mw *channelWriter) OpenedExtras() map[string]any {
+	return map[string]any{}
+}
+
+func TestReportProgress(t *testing.T) {
+	t.Run("RateLimit", func(t *testing.T) {
+		pc, channel := newTestContext(t, []string{"asset"}, func(o *Options[map[string]any]) {
+			o.Progress.Interval = time.Hour
+		})
+
+		for done := int64(0); done <= 10; done++ {
+			if err := pc.ReportProgress(done, 10, "rows"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		messages := channel.Messages(MethodLog)
+		if len(messages) != 2 {
+			t.Fatalf("Expected first and final progress, got %d messages", len(messages))
+		}
+
+		expected := []string{"progress 0/10 (0.0%): rows", "progress 10/10 (100.0%): rows"}
+
+		for i, msg := range messages {
+			params, _ := msg["params"].(map[string]any)
+			if params["message"] != expected[i] || params["level"] != string(LogLevelInfo) {
+				t.Errorf("Expected %q, got %v", expected[i], params)
+			}
+		}
+	})
+
+	t.Run("DefaultInterval", func(t *testing.T) {
+		pc, channel := newTestContext(t, []string{"asset"}, func(o *Options[map[string]any]) {
+			o.Progress = ProgressOptions{Mode: ProgressModeCustom}
+		})
+
+		for done := int64(0); done < 5; done++ {
+			if err := pc.ReportProgress(done, 10, ""); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		if messages := channel.Messages(MethodReportCustomMessage); len(messages) != 1 {
+			t.Fatalf("Expected progress to be rate limited, got %d messages", len(messages))
+		}
+	})
+
+	t.Run("Custom", func(t *testing.T) {
+		pc, channel := newTestContext(t, []string{"asset"}, func(o *Options[map[string]any]) {
+			o.Progress.Mode = ProgressModeCustom
+		})
+
+		if err := pc.ReportProgress(3, 0, ""); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		messages := channel.Messages(MethodReportCustomMessage)
+		if len(messages) != 1 {
+			t.Fatalf("Expected 1 custom message, got %d", len(messages))
+		}
+
+		params, _ := messages[0]["params"].(map[string]any)
+		envelope, _ := params["payload"].(map[string]any)
+		payload, _ := envelope["payload"].(map[string]any)
+
+		if envelope["type"] != "progress" || payload["done"] != float64(3) || payload["heartbeat"] != false {
+			t.Fatalf("Unexpected progress payload: %v", envelope)
+		}
+	})
+}
+
+func TestHeartbeat(t *testing.T) {
+	pc, channel := newTestContext(t, []string{"asset"}, func(o *Options[map[string]any]) {
+		o.Progress.HeartbeatInterval = time.Millisecond
+	})
+
+	if err := pc.ReportProgress(1, 4, "step"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(channel.Messages(MethodLog)) < 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected heartbeats")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := pc.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	messages := channel.Messages(MethodLog)
+
+	params, _ := messages[1]["params"].(map[string]any)
+	if message, _ := params["message"].(string); !strings.HasPrefix(message, "heartbeat: running for") || !strings.HasSuffix(message, "progress 1/4 (25.0%): step") {
+		t.Fatalf("Unexpected heartbeat: %q", message)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	if len(channel.Messages(MethodLog)) != len(messages) {
+		t.Fatal("Expected heartbeats to stop on close")
+	}
+
+	channel.mu.Lock()
+	last := channel.messages[len(channel.messages)-1]
+	channel.mu.Unlock()
+
+	if last["method"] != string(MethodClosed) {
+		t.Fatalf("Expected closed to be the last message, got %v", last["method"])
+	}
+}
+
+func TestHeartbeatStalled(t *testing.T) {
+	channel := &stallingChannel{stalled: make(chan struct{}), release: make(chan struct{})}
+
+	pc, _ := newTestContext(t, []string{"asset"}, func(o *Options[map[string]any]) {
+		o.MessageWriter = &channelWriter{channel: channel}
+		o.Progress.HeartbeatInterval = time.Millisecond
+	})
+
+	<-channel.stalled
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := pc.CloseContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected close to give up at the deadline, got %v", err)
+	}
+
+	close(channel.release)
+
+	if err := pc.Close(); err != nil || !pc.IsClosed() {
+		t.Fatalf("Expected close to succeed after the stall, got %v", err)
+	}
+}
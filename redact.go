@@ -0,0 +1,310 @@
+package dagsterpipes
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DefaultRedactionReplacement is the text that replaces redacted secrets.
+const DefaultRedactionReplacement = "[REDACTED]"
+
+// RedactorOptions defines configuration options for creating a new Redactor.
+type RedactorOptions struct {
+	Replacement string           // Text substituted for every redacted secret.
+	Secrets     []string         // Literal secret values to redact.
+	Patterns    []*regexp.Regexp // Patterns whose matches are redacted.
+}
+
+// Redactor scrubs registered secret values and patterns from outgoing messages.
+// If a pattern contains a named group "secret", only that group is redacted,
+// which allows keeping surrounding context such as "password=[REDACTED]".
+type Redactor struct {
+	mu          sync.RWMutex      // Protects the registered secrets and patterns.
+	replacement string            // Text substituted for every redacted secret.
+	secrets     []string          // Registered literal secret values.
+	patterns    []*regexp.Regexp  // Registered patterns.
+	replacer    *strings.Replacer // Cached replacer for the literal secrets.
+}
+
+// NewRedactor creates a new Redactor using the provided configuration functions.
+func NewRedactor(optFns ...func(o *RedactorOptions)) *Redactor {
+	opts := RedactorOptions{
+		Replacement: DefaultRedactionReplacement,
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	r := &Redactor{replacement: opts.Replacement}
+	r.AddSecrets(opts.Secrets...)
+	r.AddPatterns(opts.Patterns...)
+
+	return r
+}
+
+// AddSecrets registers literal secret values. Empty values are ignored.
+func (r *Redactor) AddSecrets(secrets ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+
+		r.secrets = append(r.secrets, secret)
+	}
+
+	r.replacer = nil
+}
+
+// AddSecretsFromEnv registers the values of the given environment variables as secrets.
+// Variables that are unset or empty are ignored.
+func (r *Redactor) AddSecretsFromEnv(names ...string) {
+	secrets := make([]string, 0, len(names))
+	for _, name := range names {
+		secrets = append(secrets, os.Getenv(name))
+	}
+
+	r.AddSecrets(secrets...)
+}
+
+// AddPatterns registers patterns whose matches are redacted.
+func (r *Redactor) AddPatterns(patterns ...*regexp.Regexp) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, pattern := range patterns {
+		if pattern == nil {
+			continue
+		}
+
+		r.patterns = append(r.patterns, pattern)
+	}
+}
+
+// Redact returns s with all registered secrets and pattern matches replaced.
+func (r *Redactor) Redact(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+
+	replacer, patterns := r.snapshot()
+
+	if replacer != nil {
+		s = replacer.Replace(s)
+	}
+
+	for _, pattern := range patterns {
+		s = r.redactPattern(pattern, s)
+	}
+
+	return s
+}
+
+// snapshot returns the cached replacer and the registered patterns,
+// building the replacer if required.
+func (r *Redactor) snapshot() (*strings.Replacer, []*regexp.Regexp) {
+	r.mu.RLock()
+	replacer, patterns, secrets := r.replacer, r.patterns, r.secrets
+	r.mu.RUnlock()
+
+	if replacer != nil || len(secrets) == 0 {
+		return replacer, patterns
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.replacer == nil {
+		// Longer secrets first, so a secret containing another one is fully redacted.
+		sorted := slices.Clone(r.secrets)
+		slices.SortStableFunc(sorted, func(a, b string) int { return len(b) - len(a) })
+
+		oldnew := make([]string, 0, len(sorted)*2)
+		for _, secret := range sorted {
+			oldnew = append(oldnew, secret, r.replacement)
+		}
+
+		r.replacer = strings.NewReplacer(oldnew...)
+	}
+
+	return r.replacer, r.patterns
+}
+
+// redactPattern replaces the matches of pattern in s. If the pattern has a
+// named group "secret", only that group is replaced.
+func (r *Redactor) redactPattern(pattern *regexp.Regexp, s string) string {
+	group := pattern.SubexpIndex("secret")
+	if group < 0 {
+		return pattern.ReplaceAllLiteralString(s, r.replacement)
+	}
+
+	var b strings.Builder
+
+	last := 0
+
+	for _, match := range pattern.FindAllStringSubmatchIndex(s, -1) {
+		start, end := match[2*group], match[2*group+1]
+		if start < 0 {
+			continue
+		}
+
+		b.WriteString(s[last:start])
+		b.WriteString(r.replacement)
+
+		last = end
+	}
+
+	b.WriteString(s[last:])
+
+	return b.String()
+}
+
+// isEmpty reports whether the redactor has nothing to redact.
+func (r *Redactor) isEmpty() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.secrets) == 0 && len(r.patterns) == 0
+}
+
+// redactParams returns a redacted copy of the parameters of an outgoing message.
+// The original parameters are left untouched.
+func (r *Redactor) redactParams(params any) any {
+	if r == nil || r.isEmpty() {
+		return params
+	}
+
+	switch p := params.(type) {
+	case *Log:
+		return &Log{Message: r.Redact(p.Message), Level: p.Level}
+	case *AssetMaterialization:
+		materialization := *p
+		materialization.Metadata = r.redactMetadata(p.Metadata)
+
+		return &materialization
+	case *AssetCheck:
+		check := *p
+		check.Metadata = r.redactMetadata(p.Metadata)
+
+		return &check
+	case *CustomMessage:
+		return &CustomMessage{Payload: r.redactValue(p.Payload)}
+	case *Closed:
+		return &Closed{Exception: r.redactException(p.Exception)}
+	case *Opened[map[string]any]:
+		extras, _ := r.redactValue(p.Extras).(map[string]any)
+		return &Opened[map[string]any]{Extras: extras}
+	default:
+		return r.redactValue(params)
+	}
+}
+
+// redactMetadata normalizes the metadata and redacts the raw value of every entry.
+// Metadata that cannot be normalized is returned as is, its serialization fails anyway.
+func (r *Redactor) redactMetadata(metadata map[string]any) map[string]any {
+	normalized, err := normalizeParamMetadata(metadata)
+	if metadata == nil || err != nil {
+		return metadata
+	}
+
+	redacted := make(map[string]any, len(metadata))
+
+	for key, value := range normalized {
+		value.RawValue = r.redactValue(value.RawValue)
+		redacted[key] = value
+	}
+
+	return redacted
+}
+
+// redactException returns a redacted copy of the exception, its causes and contexts.
+func (r *Redactor) redactException(exception *Exception) *Exception {
+	if exception == nil {
+		return nil
+	}
+
+	redacted := *exception
+	redacted.Message = r.Redact(exception.Message)
+	redacted.Cause = r.redactException(exception.Cause)
+
+	if exception.Stack != nil {
+		redacted.Stack = make([]string, len(exception.Stack))
+		for i, line := range exception.Stack {
+			redacted.Stack[i] = r.Redact(line)
+		}
+	}
+
+	redacted.Context = r.redactException(exception.Context)
+
+	return &redacted
+}
+
+// redactValue returns a redacted copy of an arbitrary value. Values that are
+// not strings, maps or slices are converted through their JSON representation,
+// so that secrets nested in structs are scrubbed as well.
+func (r *Redactor) redactValue(value any) any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return r.Redact(v)
+	case MetadataValue:
+		v.RawValue = r.redactValue(v.RawValue)
+		return v
+	case []string:
+		redacted := make([]string, len(v))
+		for i, s := range v {
+			redacted[i] = r.Redact(s)
+		}
+
+		return redacted
+	case []any:
+		redacted := make([]any, len(v))
+		for i, item := range v {
+			redacted[i] = r.redactValue(item)
+		}
+
+		return redacted
+	case map[string]any:
+		redacted := make(map[string]any, len(v))
+		for key, item := range v {
+			redacted[key] = r.redactValue(item)
+		}
+
+		return redacted
+	case json.Number:
+		return v
+	}
+
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return value
+	default:
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		// Leave the value as is; serialization will report the error.
+		return value
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+
+	var decoded any
+	if err := decoder.Decode(&decoded); err != nil {
+		return value
+	}
+
+	return r.redactValue(decoded)
+}
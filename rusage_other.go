@@ -0,0 +1,8 @@
+//go:build !unix
+
+package dagsterpipes
+
+// getrusage reports that the resource usage of the process is unavailable on this platform.
+func getrusage() (rusage, bool) {
+	return rusage{}, false
+}
@@ -0,0 +1,30 @@
+//go:build unix
+
+package dagsterpipes
+
+import (
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// getrusage returns the resource usage of the process reported by getrusage(2).
+func getrusage() (rusage, bool) {
+	var ru syscall.Rusage
+
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+		return rusage{}, false
+	}
+
+	// ru_maxrss is reported in bytes on Darwin and in kilobytes elsewhere.
+	maxRSS := int64(ru.Maxrss)
+	if runtime.GOOS != "darwin" && runtime.GOOS != "ios" {
+		maxRSS *= 1024
+	}
+
+	return rusage{
+		userCPU:   time.Duration(ru.Utime.Nano()),
+		systemCPU: time.Duration(ru.Stime.Nano()),
+		maxRSS:    maxRSS,
+	}, true
+}
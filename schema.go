@@ -0,0 +1,339 @@
+package dagsterpipes
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// messageSchemaJSON is the JSON Schema of the Dagster Pipes messages.
+//
+//go:embed schema/message.schema.json
+var messageSchemaJSON []byte
+
+// loadMessageSchema parses the embedded message schema once.
+var loadMessageSchema = sync.OnceValues(func() (*jsonSchema, error) {
+	var schema jsonSchema
+	if err := json.Unmarshal(messageSchemaJSON, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse message schema: %w", err)
+	}
+
+	return &schema, nil
+})
+
+// SchemaError describes a value that does not match the message schema.
+type SchemaError struct {
+	Path    string // Path to the offending field, e.g. "$.params.metadata.rows.type".
+	Message string // Description of the mismatch.
+}
+
+// Error returns the path and description of the mismatch.
+func (e *SchemaError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// ValidateMessage validates a message against the JSON Schema of the Dagster Pipes
+// protocol. Returns a *SchemaError pointing to the first offending field.
+func ValidateMessage(msg Message) error {
+	schema, err := loadMessageSchema()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+
+	return schema.validate(schema, value, "$")
+}
+
+// jsonSchema represents the subset of JSON Schema used by the message schema:
+// type, enum, const, minLength, properties, required, additionalProperties,
+// items, $ref, $defs, allOf, anyOf, oneOf and if/then/else.
+type jsonSchema struct {
+	Ref                  string                 `json:"$ref"`
+	Defs                 map[string]*jsonSchema `json:"$defs"`
+	Type                 schemaTypes            `json:"type"`
+	Enum                 []json.RawMessage      `json:"enum"`
+	Const                json.RawMessage        `json:"const"`
+	MinLength            *int                   `json:"minLength"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	AllOf                []*jsonSchema          `json:"allOf"`
+	AnyOf                []*jsonSchema          `json:"anyOf"`
+	OneOf                []*jsonSchema          `json:"oneOf"`
+	If                   *jsonSchema            `json:"if"`
+	Then                 *jsonSchema            `json:"then"`
+	Else                 *jsonSchema            `json:"else"`
+	reject               bool                   // Set for the boolean schema false.
+}
+
+// UnmarshalJSON parses a schema, including the boolean schemas true and false.
+func (s *jsonSchema) UnmarshalJSON(data []byte) error {
+	var accept bool
+	if err := json.Unmarshal(data, &accept); err == nil {
+		*s = jsonSchema{reject: !accept}
+		return nil
+	}
+
+	type plain jsonSchema
+
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// schemaTypes represents the "type" keyword, either a single type or a list of types.
+type schemaTypes []string
+
+// UnmarshalJSON parses a single type or a list of types.
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// validate validates a decoded JSON value against the schema s, resolving
+// references against root. Returns the first mismatch.
+func (s *jsonSchema) validate(root *jsonSchema, value any, path string) error {
+	if s.reject {
+		return &SchemaError{Path: path, Message: "unexpected field"}
+	}
+
+	if s.Ref != "" {
+		ref, err := root.resolve(s.Ref)
+		if err != nil {
+			return err
+		}
+
+		if err := ref.validate(root, value, path); err != nil {
+			return err
+		}
+	}
+
+	if len(s.Type) > 0 && !s.Type.matches(value) {
+		return &SchemaError{Path: path, Message: fmt.Sprintf("expected %s, got %s", strings.Join(s.Type, " or "), jsonTypeName(value))}
+	}
+
+	if s.Const != nil && !jsonEqual(s.Const, value) {
+		return &SchemaError{Path: path, Message: fmt.Sprintf("expected %s, got %s", s.Const, jsonString(value))}
+	}
+
+	if s.Enum != nil && !s.inEnum(value) {
+		allowed := make([]string, len(s.Enum))
+		for i, raw := range s.Enum {
+			allowed[i] = string(raw)
+		}
+
+		return &SchemaError{Path: path, Message: fmt.Sprintf("%s is not one of %s", jsonString(value), strings.Join(allowed, ", "))}
+	}
+
+	if str, ok := value.(string); ok && s.MinLength != nil && utf8.RuneCountInString(str) < *s.MinLength {
+		return &SchemaError{Path: path, Message: fmt.Sprintf("expected at least %d characters", *s.MinLength)}
+	}
+
+	if object, ok := value.(map[string]any); ok {
+		if err := s.validateObject(root, object, path); err != nil {
+			return err
+		}
+	}
+
+	if array, ok := value.([]any); ok && s.Items != nil {
+		for i, item := range array {
+			if err := s.Items.validate(root, item, path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.validateCombinators(root, value, path)
+}
+
+// validateObject validates the required, properties and additionalProperties keywords.
+func (s *jsonSchema) validateObject(root *jsonSchema, object map[string]any, path string) error {
+	for _, name := range s.Required {
+		if _, ok := object[name]; !ok {
+			return &SchemaError{Path: joinSchemaPath(path, name), Message: "missing required field"}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(object)) {
+		property, ok := s.Properties[name]
+		if !ok {
+			property = s.AdditionalProperties
+		}
+
+		if property == nil {
+			continue
+		}
+
+		if err := property.validate(root, object[name], joinSchemaPath(path, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateCombinators validates the allOf, anyOf, oneOf and if/then/else keywords.
+func (s *jsonSchema) validateCombinators(root *jsonSchema, value any, path string) error {
+	for _, sub := range s.AllOf {
+		if err := sub.validate(root, value, path); err != nil {
+			return err
+		}
+	}
+
+	if len(s.AnyOf) > 0 && !slices.ContainsFunc(s.AnyOf, func(sub *jsonSchema) bool {
+		return sub.validate(root, value, path) == nil
+	}) {
+		return &SchemaError{Path: path, Message: "does not match any of the allowed schemas"}
+	}
+
+	if len(s.OneOf) > 0 {
+		matches := 0
+
+		for _, sub := range s.OneOf {
+			if sub.validate(root, value, path) == nil {
+				matches++
+			}
+		}
+
+		if matches != 1 {
+			return &SchemaError{Path: path, Message: fmt.Sprintf("expected exactly one matching schema, got %d", matches)}
+		}
+	}
+
+	if s.If != nil {
+		branch := s.Else
+		if s.If.validate(root, value, path) == nil {
+			branch = s.Then
+		}
+
+		if branch != nil {
+			return branch.validate(root, value, path)
+		}
+	}
+
+	return nil
+}
+
+// resolve resolves a local reference of the form "#/$defs/<name>".
+func (s *jsonSchema) resolve(ref string) (*jsonSchema, error) {
+	name, ok := strings.CutPrefix(ref, "#/$defs/")
+	if !ok {
+		return nil, fmt.Errorf("unsupported schema reference %q", ref)
+	}
+
+	def, ok := s.Defs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown schema reference %q", ref)
+	}
+
+	return def, nil
+}
+
+// inEnum reports whether the value equals one of the enum values.
+func (s *jsonSchema) inEnum(value any) bool {
+	for _, raw := range s.Enum {
+		if jsonEqual(raw, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matches reports whether the value has one of the types.
+func (t schemaTypes) matches(value any) bool {
+	actual := jsonTypeName(value)
+
+	for _, expected := range t {
+		if expected == actual || (expected == "number" && actual == "integer") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// jsonTypeName returns the JSON Schema type of a decoded JSON value.
+func jsonTypeName(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if f, err := v.Float64(); err == nil && f == math.Trunc(f) {
+			return "integer"
+		}
+
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// jsonEqual reports whether the raw JSON and the decoded value are equal.
+func jsonEqual(raw json.RawMessage, value any) bool {
+	var expected any
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&expected); err != nil {
+		return false
+	}
+
+	return jsonString(expected) == jsonString(value)
+}
+
+// jsonString returns the JSON representation of a decoded value.
+func jsonString(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(data)
+}
+
+// schemaPathIdentifier matches field names that can be appended to a path with a dot.
+var schemaPathIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// joinSchemaPath appends a field name to a path, quoting names that are not identifiers.
+func joinSchemaPath(path, name string) string {
+	if schemaPathIdentifier.MatchString(name) {
+		return path + "." + name
+	}
+
+	return path + "[" + strconv.Quote(name) + "]"
+}
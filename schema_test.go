@@ -0,0 +1,140 @@
+package dagsterpipes
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestValidateMessage(t *testing.T) {
+	tests := []struct {
+		name   string
+		method Method
+		params any
+		path   string // Expected path of the schema error, empty if valid.
+	}{
+		{
+			name:   "Opened",
+			method: MethodOpened,
+			params: &Opened[map[string]any]{Extras: map[string]any{"key": "value"}},
+		},
+		{
+			name:   "Closed",
+			method: MethodClosed,
+			params: &Closed{Exception: NewException(errors.New("boom"), true)},
+		},
+		{
+			name:   "Log",
+			method: MethodLog,
+			params: &Log{Message: "hello", Level: "INFO"},
+		},
+		{
+			name:   "LogLevel",
+			method: MethodLog,
+			params: &Log{Message: "hello", Level: "VERBOSE"},
+			path:   "$.params.level",
+		},
+		{
+			name:   "Materialization",
+			method: MethodReportAssetMaterialization,
+			params: (&AssetMaterialization{AssetKey: "asset", Metadata: map[string]any{"owner": "team"}}).
+				WithRowCount(3).
+				WithColumnSchema(TableSchema{Columns: []TableColumn{{Name: "id", Type: "int"}}}),
+		},
+		{
+			name:   "MetadataType",
+			method: MethodReportAssetMaterialization,
+			params: &AssetMaterialization{AssetKey: "asset", Metadata: map[string]any{"rows": MetadataValue{RawValue: 3, Type: "number"}}},
+			path:   "$.params.metadata.rows.type",
+		},
+		{
+			name:   "MetadataRawValue",
+			method: MethodReportAssetMaterialization,
+			params: &AssetMaterialization{AssetKey: "asset", Metadata: map[string]any{"dagster/row_count": MetadataValue{RawValue: "3", Type: MetadataTypeInt}}},
+			path:   `$.params.metadata["dagster/row_count"].raw_value`,
+		},
+		{
+			name:   "Check",
+			method: MethodReportAssetCheck,
+			params: &AssetCheck{AssetKey: "asset", CheckName: "check", Passed: true, Serverity: AssetCheckSeverityWarn},
+		},
+		{
+			name:   "CheckName",
+			method: MethodReportAssetCheck,
+			params: &AssetCheck{AssetKey: "asset", Passed: true, Serverity: AssetCheckSeverityWarn},
+			path:   "$.params.check_name",
+		},
+		{
+			name:   "MisspelledParam",
+			method: MethodReportCustomMessage,
+			params: map[string]any{"paylaod": 1},
+			path:   "$.params.payload",
+		},
+		{
+			name:   "UnknownParam",
+			method: MethodLog,
+			params: map[string]any{"message": "hello", "level": "INFO", "extra": true},
+			path:   "$.params.extra",
+		},
+		{
+			name:   "Method",
+			method: "report_asset",
+			params: map[string]any{},
+			path:   "$.method",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMessage(Message{DagsterPipesVersion: ProtocolVersion, Method: tt.method, Params: tt.params})
+
+			if tt.path == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			var schemaErr *SchemaError
+			if !errors.As(err, &schemaErr) {
+				t.Fatalf("Expected schema error, got %v", err)
+			}
+
+			if schemaErr.Path != tt.path {
+				t.Fatalf("Expected path %s, got %s (%v)", tt.path, schemaErr.Path, err)
+			}
+		})
+	}
+}
+
+func TestStrictMode(t *testing.T) {
+	t.Run("DefaultInTests", func(t *testing.T) {
+		pc, channel := newTestContext(t, []string{"asset"})
+
+		err := pc.writeMessage(context.Background(), MethodLog, map[string]any{"msg": "hello"})
+
+		var schemaErr *SchemaError
+		if !errors.As(err, &schemaErr) || schemaErr.Path != "$.params.message" {
+			t.Fatalf("Expected schema error for $.params.message, got %v", err)
+		}
+
+		if len(channel.Messages(MethodLog)) != 0 {
+			t.Fatal("Expected invalid message not to be written")
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		pc, channel := newTestContext(t, []string{"asset"}, func(o *Options[map[string]any]) {
+			o.Strict = false
+		})
+
+		if err := pc.writeMessage(context.Background(), MethodLog, map[string]any{"msg": "hello"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(channel.Messages(MethodLog)) != 1 {
+			t.Fatal("Expected message to be written")
+		}
+	})
+}
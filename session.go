@@ -1,13 +1,49 @@
 package dagsterpipes
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
 // RunFunc defines a function type that processes a Dagster Pipes context.
 // It should return an error if any issues occur during execution.
 type RunFunc[T any] func(context *Context[T]) error
 
+// RunContextFunc defines a function type that processes a Dagster Pipes context
+// and honors the cancellation of ctx, which is canceled when the run is interrupted.
+type RunContextFunc[T any] func(ctx context.Context, pc *Context[T]) error
+
+// DefaultGracePeriod is the default time a run has to return after it was interrupted by a signal.
+const DefaultGracePeriod = 10 * time.Second
+
+// PanicMode controls how a Session continues after recovering a panic.
+type PanicMode int
+
+const (
+	// PanicModeRepanic re-panics with the original value after closing the context.
+	PanicModeRepanic PanicMode = iota
+
+	// PanicModeExit exits the process with ExitCodePanic after closing the context.
+	PanicModeExit
+)
+
+// osExit exits the process. It is replaced in tests.
+var osExit = os.Exit
+
 // Session represents a managed session for interacting with a Dagster Pipes context.
 // It ensures proper handling of the context lifecycle.
 type Session[T any] struct {
-	context *Context[T] // The underlying context for the session.
+	context   *Context[T]    // The underlying context for the session.
+	wg        sync.WaitGroup // Tracks the goroutines started with Go.
+	errOnce   sync.Once      // Guards goErr.
+	goErr     error          // The first error returned by a goroutine started with Go.
+	panicOnce sync.Once      // Ensures a panic or interrupt is reported only once.
 }
 
 // New initializes a new Session by creating and opening a Dagster Pipes context.
@@ -27,25 +63,212 @@ func (s *Session[T]) Context() *Context[T] {
 	return s.context
 }
 
-// Run executes the provided RunFunc with the session's context.
-// If the RunFunc encounters an error, the error is reported using the context's
-// ReportException method. If reporting the error also fails, that error is returned.
-// Otherwise, nil is returned.
+// Run executes the provided RunFunc with the session's context and waits for
+// the goroutines started with Go.
+// If the RunFunc or a goroutine encounters an error, the error is reported using
+// the context's ReportException method and returned. If reporting the error also
+// fails, both errors are returned.
+//
+// A panic in the RunFunc is recovered and reported as exception with the panic
+// value and stack trace. The context is closed and, depending on Options.PanicMode,
+// the panic is resumed or the process exits with a non-zero exit code.
 func (s *Session[T]) Run(fn RunFunc[T]) error {
-	return s.runWithContext(fn)
+	return s.RunContext(context.Background(), func(_ context.Context, pc *Context[T]) error {
+		return fn(pc)
+	})
+}
+
+// RunContext executes the provided RunContextFunc like Run, passing a context
+// derived from ctx that carries the pipes context, see FromContext.
+//
+// Unless Options.DisableSignalHandling is set, SIGINT and SIGTERM are trapped
+// while the function runs and cancel its context with a cause matching
+// ErrInterrupted. If the function does not return within Options.GracePeriod, or
+// a second signal arrives, the session sends a "closed" message with an
+// interrupted exception, closes the message channel and exits the process with
+// ExitCodeInterrupted. Closing is bounded by another grace period. If the function returns in time, the interruption is
+// reported and returned as its error.
+func (s *Session[T]) RunContext(ctx context.Context, fn RunContextFunc[T]) error {
+	defer func() {
+		if value := recover(); value != nil {
+			s.handlePanic(value)
+		}
+	}()
+
+	ctx, cancel := context.WithCancelCause(WithContext(ctx, s.context))
+	defer cancel(nil)
+
+	if s.context.signalHandling {
+		stop := s.handleSignals(cancel)
+		defer stop()
+	}
+
+	return s.runWithContext(ctx, fn)
+}
+
+// Go runs fn in a new goroutine with the session's context. Errors are reported
+// by Run, which waits for the goroutine, and panics are handled like panics in Run.
+func (s *Session[T]) Go(fn RunFunc[T]) {
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		defer func() {
+			if value := recover(); value != nil {
+				s.handlePanic(value)
+			}
+		}()
+
+		if err := fn(s.context); err != nil {
+			s.errOnce.Do(func() {
+				s.goErr = err
+			})
+		}
+	}()
 }
 
 // runWithContext is an internal helper that encapsulates error handling logic
 // for executing the RunFunc and reporting exceptions.
-func (s *Session[T]) runWithContext(fn RunFunc[T]) error {
-	if err := fn(s.context); err != nil {
+func (s *Session[T]) runWithContext(ctx context.Context, fn RunContextFunc[T]) error {
+	err := fn(ctx, s.context)
+
+	s.wg.Wait()
+
+	if err == nil {
+		err = s.goErr
+	}
+
+	if cause := context.Cause(ctx); errors.Is(cause, ErrInterrupted) && !errors.Is(err, ErrInterrupted) {
+		if err == nil || errors.Is(err, context.Canceled) {
+			err = cause
+		} else {
+			err = errors.Join(cause, err)
+		}
+	}
+
+	if err != nil {
 		// Attempt to report the exception
 		if reportErr := s.context.ReportException(err); reportErr != nil {
-			return reportErr
+			return errors.Join(err, reportErr)
+		}
+	}
+
+	return err
+}
+
+// handleSignals traps SIGINT and SIGTERM until the returned function is called.
+// The first signal cancels the run, the grace period or a second signal force the
+// shutdown of the session.
+func (s *Session[T]) handleSignals(cancel context.CancelCauseFunc) func() {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+
+	go func() {
+		var sig os.Signal
+
+		select {
+		case sig = <-signals:
+		case <-done:
+			return
 		}
+
+		cause := &signalError{signal: sig}
+		cancel(cause)
+
+		timer := time.NewTimer(s.context.gracePeriod)
+		defer timer.Stop()
+
+		select {
+		case <-done:
+			return
+		case <-timer.C:
+		case <-signals:
+		}
+
+		s.interrupt(cause)
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// interrupt closes the context with an interrupted exception and exits the process.
+// Closing gets at most another grace period, the process exits even if a stalled
+// write, heartbeat or concurrent close blocks it.
+func (s *Session[T]) interrupt(cause error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.context.gracePeriod)
+	defer cancel()
+
+	closed := make(chan struct{})
+
+	go func() {
+		defer close(closed)
+
+		s.panicOnce.Do(func() {
+			s.context.setException(NewException(cause, false))
+
+			if err := s.context.CloseContext(ctx); err != nil {
+				s.context.logger.Error(fmt.Sprintf("failed to close context after interrupt: %v", err))
+			}
+		})
+	}()
+
+	select {
+	case <-closed:
+	case <-ctx.Done():
+		s.context.logger.Error("failed to close context after interrupt: timed out")
+	}
+
+	osExit(ExitCodeInterrupted)
+}
+
+// signalError is the cause of runs interrupted by a signal.
+type signalError struct {
+	signal os.Signal // The received signal.
+}
+
+// Error returns the received signal.
+func (e *signalError) Error() string {
+	return fmt.Sprintf("%v: received %v", ErrInterrupted, e.signal)
+}
+
+// Is reports whether the target is ErrInterrupted.
+func (e *signalError) Is(target error) bool {
+	return target == ErrInterrupted
+}
+
+// ExceptionName returns the name of the interrupted exception.
+func (e *signalError) ExceptionName() string {
+	return "Interrupted"
+}
+
+// handlePanic reports a recovered panic, closes the context and resumes the
+// panic or exits, depending on the panic mode.
+func (s *Session[T]) handlePanic(value any) {
+	// The stack trace is captured before any other call, so that it starts at the panic.
+	exception := NewPanicException(value, func(o *ExceptionOptions) {
+		*o = s.context.exceptionOptions
+	})
+
+	s.panicOnce.Do(func() {
+		s.context.setException(exception)
+
+		if err := s.context.Close(); err != nil {
+			s.context.logger.Error(fmt.Sprintf("failed to close context after panic: %v", err))
+		}
+	})
+
+	if s.context.panicMode == PanicModeExit {
+		osExit(ExitCodePanic)
+		return
 	}
 
-	return nil
+	panic(value)
 }
 
 // Close finalizes the session by closing the associated context.
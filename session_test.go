@@ -0,0 +1,292 @@
+package dagsterpipes
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestSession creates a Session for a single asset backed by a recording channel.
+func newTestSession(t *testing.T, optFns ...func(o *Options[map[string]any])) (*Session[map[string]any], *testMessageChannel) {
+	t.Helper()
+
+	channel := &testMessageChannel{}
+
+	fns := append([]func(o *Options[map[string]any]){func(o *Options[map[string]any]) {
+		o.ParamsLoader = &testParamsLoader{data: &ContextData[map[string]any]{AssetKeys: []string{"asset"}, RunID: "run"}}
+		o.MessageWriter = &testMessageWriter{channel: channel}
+		o.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}}, optFns...)
+
+	session, err := New(fns...)
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	return session, channel
+}
+
+// closedException returns the exception of the recorded closed message.
+func closedException(t *testing.T, channel *testMessageChannel) map[string]any {
+	t.Helper()
+
+	messages := channel.Messages(MethodClosed)
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 closed message, got %d", len(messages))
+	}
+
+	params, _ := messages[0]["params"].(map[string]any)
+	exception, _ := params["exception"].(map[string]any)
+
+	return exception
+}
+
+// stubOSExit replaces osExit for the duration of the test and returns the recorded exit codes.
+func stubOSExit(t *testing.T) *[]int {
+	t.Helper()
+
+	var codes []int
+
+	original := osExit
+	osExit = func(code int) { codes = append(codes, code) }
+
+	t.Cleanup(func() { osExit = original })
+
+	return &codes
+}
+
+// panickingStep panics, to verify that stack traces start at the panic.
+func panickingStep() {
+	panic("boom")
+}
+
+func TestSession(t *testing.T) {
+	t.Run("Error", func(t *testing.T) {
+		session, channel := newTestSession(t)
+
+		if err := session.Run(func(_ *Context[map[string]any]) error {
+			return errors.New("failed")
+		}); err == nil || err.Error() != "failed" {
+			t.Fatalf("Expected original error, got %v", err)
+		}
+
+		if err := session.Close(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if exception := closedException(t, channel); exception["message"] != "failed" {
+			t.Fatalf("Expected reported error, got %v", exception)
+		}
+	})
+
+	t.Run("Repanic", func(t *testing.T) {
+		session, channel := newTestSession(t)
+
+		func() {
+			defer func() {
+				if value := recover(); value != "boom" {
+					t.Fatalf("Expected re-panic with original value, got %v", value)
+				}
+			}()
+
+			_ = session.Run(func(_ *Context[map[string]any]) error {
+				panickingStep()
+				return nil
+			})
+		}()
+
+		if !session.Context().IsClosed() {
+			t.Fatal("Expected context to be closed")
+		}
+
+		exception := closedException(t, channel)
+		if exception["name"] != PanicExceptionName || exception["message"] != "boom" {
+			t.Fatalf("Unexpected exception: %v", exception)
+		}
+
+		stack, _ := exception["stack"].([]any)
+		if len(stack) == 0 || !strings.Contains(stack[0].(string), "panickingStep") {
+			t.Fatalf("Expected stack trace to start at the panic, got %v", stack)
+		}
+	})
+
+	t.Run("Exit", func(t *testing.T) {
+		codes := stubOSExit(t)
+
+		session, channel := newTestSession(t, func(o *Options[map[string]any]) {
+			o.PanicMode = PanicModeExit
+		})
+
+		_ = session.Run(func(_ *Context[map[string]any]) error {
+			panic(errors.New("boom"))
+		})
+
+		if len(*codes) != 1 || (*codes)[0] != ExitCodePanic {
+			t.Fatalf("Expected exit code %d, got %v", ExitCodePanic, *codes)
+		}
+
+		exception := closedException(t, channel)
+		if cause, _ := exception["cause"].(map[string]any); cause["message"] != "boom" {
+			t.Fatalf("Expected panic error as cause, got %v", exception)
+		}
+	})
+
+	t.Run("Go", func(t *testing.T) {
+		codes := stubOSExit(t)
+
+		session, channel := newTestSession(t, func(o *Options[map[string]any]) {
+			o.PanicMode = PanicModeExit
+		})
+
+		if err := session.Run(func(_ *Context[map[string]any]) error {
+			session.Go(func(_ *Context[map[string]any]) error {
+				panic("boom in goroutine")
+			})
+
+			return nil
+		}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(*codes) != 1 {
+			t.Fatalf("Expected exit, got %v", *codes)
+		}
+
+		if exception := closedException(t, channel); exception["message"] != "boom in goroutine" {
+			t.Fatalf("Unexpected exception: %v", exception)
+		}
+	})
+
+	t.Run("GoError", func(t *testing.T) {
+		session, channel := newTestSession(t)
+
+		if err := session.Run(func(_ *Context[map[string]any]) error {
+			session.Go(func(_ *Context[map[string]any]) error {
+				return errors.New("failed in goroutine")
+			})
+
+			return nil
+		}); err == nil {
+			t.Fatal("Expected goroutine error")
+		}
+
+		if err := session.Close(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if exception := closedException(t, channel); exception["message"] != "failed in goroutine" {
+			t.Fatalf("Unexpected exception: %v", exception)
+		}
+	})
+}
+
+func TestSessionInterruptStalled(t *testing.T) {
+	codes := stubOSExit(t)
+	channel := &stallingChannel{stalled: make(chan struct{}), release: make(chan struct{})}
+
+	t.Cleanup(func() { close(channel.release) })
+
+	session, _ := newTestSession(t, func(o *Options[map[string]any]) {
+		o.MessageWriter = &channelWriter{channel: channel}
+		o.GracePeriod = 10 * time.Millisecond
+	})
+
+	go func() {
+		_ = session.Context().LogInfo("stalled")
+	}()
+
+	<-channel.stalled
+
+	session.interrupt(&signalError{signal: os.Interrupt})
+
+	if len(*codes) != 1 || (*codes)[0] != ExitCodeInterrupted {
+		t.Fatalf("Expected exit code %d despite the stalled write, got %v", ExitCodeInterrupted, *codes)
+	}
+}
+
+func TestSessionSignals(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Signals cannot be sent to the own process on Windows")
+	}
+
+	interrupt := func(t *testing.T) {
+		t.Helper()
+
+		process, err := os.FindProcess(os.Getpid())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if err := process.Signal(os.Interrupt); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	t.Run("Cooperative", func(t *testing.T) {
+		session, channel := newTestSession(t)
+
+		err := session.RunContext(context.Background(), func(ctx context.Context, _ *Context[map[string]any]) error {
+			interrupt(t)
+			<-ctx.Done()
+
+			return ctx.Err()
+		})
+
+		if !errors.Is(err, ErrInterrupted) || ExitCode(err) != ExitCodeInterrupted {
+			t.Fatalf("Expected interrupted error, got %v", err)
+		}
+
+		if err := session.Close(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if exception := closedException(t, channel); exception["name"] != "Interrupted" {
+			t.Fatalf("Expected interrupted exception, got %v", exception)
+		}
+	})
+
+	t.Run("GracePeriod", func(t *testing.T) {
+		exited := make(chan int, 1)
+
+		original := osExit
+		osExit = func(code int) { exited <- code }
+
+		t.Cleanup(func() { osExit = original })
+
+		session, channel := newTestSession(t, func(o *Options[map[string]any]) {
+			o.GracePeriod = 10 * time.Millisecond
+		})
+
+		release := make(chan struct{})
+
+		go func() {
+			if code := <-exited; code != ExitCodeInterrupted {
+				t.Errorf("Expected exit code %d, got %d", ExitCodeInterrupted, code)
+			}
+
+			close(release)
+		}()
+
+		_ = session.RunContext(context.Background(), func(_ context.Context, _ *Context[map[string]any]) error {
+			interrupt(t)
+			<-release
+
+			return nil
+		})
+
+		if !channel.isClosed() {
+			t.Fatal("Expected channel to be closed")
+		}
+
+		exception := closedException(t, channel)
+		if exception["name"] != "Interrupted" || !strings.Contains(exception["message"].(string), "interrupt") {
+			t.Fatalf("Expected interrupted exception, got %v", exception)
+		}
+	})
+}
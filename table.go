@@ -0,0 +1,277 @@
+package dagsterpipes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// TableColumnConstraints describes constraints on the values of a table column.
+type TableColumnConstraints struct {
+	NotNull bool     // Whether the column must not contain null values.
+	Unique  bool     // Whether the values of the column are unique.
+	Other   []string // Free-form descriptions of further constraints.
+}
+
+// MarshalJSON serializes TableColumnConstraints into the format expected by Dagster.
+func (c TableColumnConstraints) MarshalJSON() ([]byte, error) {
+	other := c.Other
+	if other == nil {
+		other = []string{}
+	}
+
+	return json.Marshal(struct {
+		Nullable bool     `json:"nullable"`
+		Unique   bool     `json:"unique"`
+		Other    []string `json:"other"`
+	}{
+		Nullable: !c.NotNull,
+		Unique:   c.Unique,
+		Other:    other,
+	})
+}
+
+// TableColumn describes a column of a table.
+type TableColumn struct {
+	Name        string                  // The name of the column.
+	Type        string                  // The type of the column, e.g. "string" or "int". Defaults to "string".
+	Description string                  // Optional description of the column.
+	Tags        map[string]string       // Optional tags of the column.
+	Constraints *TableColumnConstraints // Optional constraints on the column values.
+}
+
+// MarshalJSON serializes TableColumn into the format expected by Dagster.
+func (c TableColumn) MarshalJSON() ([]byte, error) {
+	columnType := c.Type
+	if columnType == "" {
+		columnType = "string"
+	}
+
+	return json.Marshal(struct {
+		Name        string                  `json:"name"`
+		Type        string                  `json:"type"`
+		Description string                  `json:"description,omitempty"`
+		Tags        map[string]string       `json:"tags,omitempty"`
+		Constraints *TableColumnConstraints `json:"constraints,omitempty"`
+	}{
+		Name:        c.Name,
+		Type:        columnType,
+		Description: c.Description,
+		Tags:        c.Tags,
+		Constraints: c.Constraints,
+	})
+}
+
+// TableSchema describes the columns of a table.
+type TableSchema struct {
+	Columns []TableColumn `json:"columns"` // The columns of the table.
+}
+
+// Validate checks that the schema has uniquely named columns.
+func (s TableSchema) Validate() error {
+	names := make(map[string]struct{}, len(s.Columns))
+
+	for _, column := range s.Columns {
+		if column.Name == "" {
+			return errors.New("table column without name")
+		}
+
+		if _, exists := names[column.Name]; exists {
+			return fmt.Errorf("duplicate table column %q", column.Name)
+		}
+
+		names[column.Name] = struct{}{}
+	}
+
+	return nil
+}
+
+// ValidateRecord checks that the record matches the schema: all fields must be
+// columns of the schema with scalar values of the column type, and columns
+// constrained as not null must be set.
+func (s TableSchema) ValidateRecord(record TableRecord) error {
+	columns := make(map[string]TableColumn, len(s.Columns))
+	for _, column := range s.Columns {
+		columns[column.Name] = column
+	}
+
+	for name, value := range record {
+		column, ok := columns[name]
+		if !ok {
+			return fmt.Errorf("field %q is not a column of the schema", name)
+		}
+
+		if err := validateTableValue(column.Type, value); err != nil {
+			return fmt.Errorf("field %q: %w", name, err)
+		}
+	}
+
+	for _, column := range s.Columns {
+		if column.Constraints != nil && column.Constraints.NotNull && record[column.Name] == nil {
+			return fmt.Errorf("field %q must not be null", column.Name)
+		}
+	}
+
+	return nil
+}
+
+// TableRecord represents a row of a table, mapping column names to scalar values.
+type TableRecord map[string]any
+
+// Table represents rows of a table together with their schema.
+type Table struct {
+	Records []TableRecord // The rows of the table.
+	Schema  TableSchema   // The schema of the table.
+}
+
+// MarshalJSON serializes Table into the format expected by Dagster.
+func (t Table) MarshalJSON() ([]byte, error) {
+	records := t.Records
+	if records == nil {
+		records = []TableRecord{}
+	}
+
+	columns := t.Schema.Columns
+	if columns == nil {
+		columns = []TableColumn{}
+	}
+
+	return json.Marshal(struct {
+		Records []TableRecord `json:"records"`
+		Schema  []TableColumn `json:"schema"`
+	}{
+		Records: records,
+		Schema:  columns,
+	})
+}
+
+// Validate checks the schema and that all records match it.
+func (t Table) Validate() error {
+	if err := t.Schema.Validate(); err != nil {
+		return err
+	}
+
+	for i, record := range t.Records {
+		if err := t.Schema.ValidateRecord(record); err != nil {
+			return fmt.Errorf("record %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// TableColumnDep identifies a column of an upstream asset.
+type TableColumnDep struct {
+	AssetKey   string `json:"asset_key"`   // The key of the upstream asset, e.g. "prefix/name".
+	ColumnName string `json:"column_name"` // The name of the upstream column.
+}
+
+// TableColumnLineage describes the upstream columns each column is derived from.
+type TableColumnLineage struct {
+	DepsByColumn map[string][]TableColumnDep `json:"deps_by_column"` // Upstream columns by column name.
+}
+
+// Validate checks that all dependencies reference an asset and a column.
+func (l TableColumnLineage) Validate() error {
+	for column, deps := range l.DepsByColumn {
+		for _, dep := range deps {
+			if dep.AssetKey == "" || dep.ColumnName == "" {
+				return fmt.Errorf("column %q: dependency requires an asset key and a column name", column)
+			}
+		}
+	}
+
+	return nil
+}
+
+// TableMetadata creates a table metadata value from records and their schema.
+func TableMetadata(records []TableRecord, schema TableSchema) MetadataValue {
+	return MetadataValue{RawValue: Table{Records: records, Schema: schema}, Type: MetadataTypeTable}
+}
+
+// TableSchemaMetadata creates a table schema metadata value.
+func TableSchemaMetadata(schema TableSchema) MetadataValue {
+	return MetadataValue{RawValue: schema, Type: MetadataTypeTableSchema}
+}
+
+// TableColumnLineageMetadata creates a column lineage metadata value.
+func TableColumnLineageMetadata(lineage TableColumnLineage) MetadataValue {
+	return MetadataValue{RawValue: lineage, Type: MetadataTypeTableColumnLineage}
+}
+
+// validateTable validates the raw value of table metadata.
+func validateTable(raw any) error {
+	switch v := raw.(type) {
+	case Table:
+		return v.Validate()
+	case *Table:
+		return v.Validate()
+	default:
+		return validateJSONContainer(raw)
+	}
+}
+
+// validateTableSchema validates the raw value of table schema metadata.
+func validateTableSchema(raw any) error {
+	switch v := raw.(type) {
+	case TableSchema:
+		return v.Validate()
+	case *TableSchema:
+		return v.Validate()
+	default:
+		return validateJSONContainer(raw)
+	}
+}
+
+// validateTableColumnLineage validates the raw value of column lineage metadata.
+func validateTableColumnLineage(raw any) error {
+	switch v := raw.(type) {
+	case TableColumnLineage:
+		return v.Validate()
+	case *TableColumnLineage:
+		return v.Validate()
+	default:
+		return validateJSONContainer(raw)
+	}
+}
+
+// validateTableValue checks that a record value is a scalar matching the column type.
+// Column types unknown to this package only require a scalar.
+func validateTableValue(columnType string, value any) error {
+	if value == nil {
+		return nil
+	}
+
+	kind := reflect.ValueOf(value).Kind()
+
+	switch kind {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+	default:
+		return fmt.Errorf("expected a string, number, bool or nil, got %T", value)
+	}
+
+	switch columnType {
+	case "", "string", "str", "text":
+		if kind != reflect.String {
+			return fmt.Errorf("expected a string, got %T", value)
+		}
+	case "int", "integer":
+		return validateInteger(value)
+	case "float", "number":
+		f, ok := toFloat64(value)
+		if !ok || math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Errorf("expected a finite number, got %v", value)
+		}
+	case "bool", "boolean":
+		if kind != reflect.Bool {
+			return fmt.Errorf("expected a bool, got %T", value)
+		}
+	}
+
+	return nil
+}
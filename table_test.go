@@ -0,0 +1,93 @@
+package dagsterpipes
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTable(t *testing.T) {
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "id", Type: "int", Constraints: &TableColumnConstraints{NotNull: true, Unique: true}},
+			{Name: "name", Description: "The name"},
+			{Name: "score", Type: "float"},
+		},
+	}
+
+	t.Run("MarshalJSON", func(t *testing.T) {
+		value := TableMetadata([]TableRecord{{"id": 1, "name": "a", "score": 0.5}}, schema)
+
+		data, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		want := `{"raw_value":{"records":[{"id":1,"name":"a","score":0.5}],"schema":[` +
+			`{"name":"id","type":"int","constraints":{"nullable":false,"unique":true,"other":[]}},` +
+			`{"name":"name","type":"string","description":"The name"},` +
+			`{"name":"score","type":"float"}]},"type":"table"}`
+		if string(data) != want {
+			t.Fatalf("Unexpected JSON:\n got %s\nwant %s", data, want)
+		}
+	})
+
+	t.Run("TableSchemaMetadata", func(t *testing.T) {
+		data, err := json.Marshal(TableSchemaMetadata(TableSchema{Columns: []TableColumn{{Name: "id", Type: "int"}}}))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if want := `{"raw_value":{"columns":[{"name":"id","type":"int"}]},"type":"table_schema"}`; string(data) != want {
+			t.Fatalf("Unexpected JSON:\n got %s\nwant %s", data, want)
+		}
+	})
+
+	t.Run("TableColumnLineageMetadata", func(t *testing.T) {
+		value := TableColumnLineageMetadata(TableColumnLineage{
+			DepsByColumn: map[string][]TableColumnDep{
+				"id": {{AssetKey: "upstream/users", ColumnName: "user_id"}},
+			},
+		})
+
+		data, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		want := `{"raw_value":{"deps_by_column":{"id":[{"asset_key":"upstream/users","column_name":"user_id"}]}},"type":"table_column_lineage"}`
+		if string(data) != want {
+			t.Fatalf("Unexpected JSON:\n got %s\nwant %s", data, want)
+		}
+
+		if err := TableColumnLineageMetadata(TableColumnLineage{
+			DepsByColumn: map[string][]TableColumnDep{"id": {{AssetKey: "upstream"}}},
+		}).Validate(); err == nil {
+			t.Fatal("Expected error for incomplete dependency")
+		}
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		valid := TableMetadata([]TableRecord{{"id": 1, "name": "a"}, {"id": 2, "score": nil}}, schema)
+		if err := valid.Validate(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		invalid := [][]TableRecord{
+			{{"id": 1, "unknown": "x"}},
+			{{"id": "1"}},
+			{{"name": "missing id"}},
+			{{"id": 1, "name": []string{"not", "scalar"}}},
+		}
+
+		for _, records := range invalid {
+			if err := TableMetadata(records, schema).Validate(); err == nil {
+				t.Fatalf("Expected validation error for %v", records)
+			}
+		}
+
+		duplicate := TableSchema{Columns: []TableColumn{{Name: "id"}, {Name: "id"}}}
+		if err := TableSchemaMetadata(duplicate).Validate(); err == nil {
+			t.Fatal("Expected error for duplicate columns")
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package dagsterpipes
+
+import (
+	"context"
+	"runtime"
+	"time"
+)
+
+// Metadata keys used for resource usage telemetry.
+const (
+	// MetadataKeyWallTime holds the wall time in seconds since the context was opened.
+	MetadataKeyWallTime = "resource_usage/wall_time_seconds"
+
+	// MetadataKeyUserCPUTime holds the user CPU time in seconds since the context was opened.
+	MetadataKeyUserCPUTime = "resource_usage/user_cpu_seconds"
+
+	// MetadataKeySystemCPUTime holds the system CPU time in seconds since the context was opened.
+	MetadataKeySystemCPUTime = "resource_usage/system_cpu_seconds"
+
+	// MetadataKeyMaxRSS holds the peak resident set size of the process in bytes.
+	MetadataKeyMaxRSS = "resource_usage/max_rss_bytes"
+
+	// MetadataKeyGCCount holds the number of garbage collections since the context was opened.
+	MetadataKeyGCCount = "resource_usage/gc_count"
+
+	// MetadataKeyGCPause holds the total GC pause time in seconds since the context was opened.
+	MetadataKeyGCPause = "resource_usage/gc_pause_seconds"
+
+	// MetadataKeyGoroutines holds the number of goroutines at the time of the report.
+	MetadataKeyGoroutines = "resource_usage/goroutines"
+
+	// MetadataKeyHeapAlloc holds the allocated heap in bytes at the time of the report.
+	MetadataKeyHeapAlloc = "resource_usage/heap_alloc_bytes"
+)
+
+// TelemetryOptions configures the collection of resource usage telemetry.
+// When enabled, the resource usage since the context was opened is attached to
+// asset materializations and summarized in a custom message before "closed".
+type TelemetryOptions struct {
+	Enabled bool // Whether resource usage is collected.
+}
+
+// ResourceUsage represents the resource usage of the process since the context was opened.
+// CPU times and the peak RSS are only available on Unix systems and zero otherwise.
+type ResourceUsage struct {
+	WallTimeSeconds   float64 `json:"wall_time_seconds"`            // Wall time since the context was opened.
+	UserCPUSeconds    float64 `json:"user_cpu_seconds,omitempty"`   // User CPU time since the context was opened.
+	SystemCPUSeconds  float64 `json:"system_cpu_seconds,omitempty"` // System CPU time since the context was opened.
+	MaxRSSBytes       int64   `json:"max_rss_bytes,omitempty"`      // Peak resident set size of the process.
+	GCCount           uint32  `json:"gc_count"`                     // Garbage collections since the context was opened.
+	GCPauseSeconds    float64 `json:"gc_pause_seconds"`             // Total GC pause time since the context was opened.
+	Goroutines        int     `json:"goroutines"`                   // Number of goroutines.
+	HeapAllocBytes    uint64  `json:"heap_alloc_bytes"`             // Allocated heap.
+	rusageUnavailable bool    // Whether CPU times and the peak RSS are unavailable on this platform.
+}
+
+// PayloadType returns the type tag of resource usage custom messages.
+func (ResourceUsage) PayloadType() string {
+	return "resource_usage"
+}
+
+// Metadata returns the resource usage as typed asset metadata.
+func (u ResourceUsage) Metadata() map[string]any {
+	metadata := map[string]any{
+		MetadataKeyWallTime:   FloatMetadata(u.WallTimeSeconds),
+		MetadataKeyGCCount:    IntMetadata(u.GCCount),
+		MetadataKeyGCPause:    FloatMetadata(u.GCPauseSeconds),
+		MetadataKeyGoroutines: IntMetadata(u.Goroutines),
+		MetadataKeyHeapAlloc:  IntMetadata(u.HeapAllocBytes),
+	}
+
+	if !u.rusageUnavailable {
+		metadata[MetadataKeyUserCPUTime] = FloatMetadata(u.UserCPUSeconds)
+		metadata[MetadataKeySystemCPUTime] = FloatMetadata(u.SystemCPUSeconds)
+		metadata[MetadataKeyMaxRSS] = IntMetadata(u.MaxRSSBytes)
+	}
+
+	return metadata
+}
+
+// rusage holds the resource usage reported by the operating system.
+type rusage struct {
+	userCPU   time.Duration // User CPU time of the process.
+	systemCPU time.Duration // System CPU time of the process.
+	maxRSS    int64         // Peak resident set size of the process in bytes.
+}
+
+// telemetrySample is a point-in-time snapshot of the resource usage.
+type telemetrySample struct {
+	time         time.Time // Time of the snapshot.
+	rusage       rusage    // Resource usage reported by the operating system.
+	rusageOK     bool      // Whether the operating system reported the resource usage.
+	numGC        uint32    // Number of completed GC cycles.
+	pauseTotalNs uint64    // Total GC pause time.
+	goroutines   int       // Number of goroutines.
+	heapAlloc    uint64    // Allocated heap.
+}
+
+// sampleTelemetry takes a snapshot of the resource usage.
+func sampleTelemetry() telemetrySample {
+	var stats runtime.MemStats
+
+	runtime.ReadMemStats(&stats)
+
+	usage, ok := getrusage()
+
+	return telemetrySample{
+		time:         time.Now(),
+		rusage:       usage,
+		rusageOK:     ok,
+		numGC:        stats.NumGC,
+		pauseTotalNs: stats.PauseTotalNs,
+		goroutines:   runtime.NumGoroutine(),
+		heapAlloc:    stats.HeapAlloc,
+	}
+}
+
+// since returns the resource usage between the start sample and s.
+func (s telemetrySample) since(start telemetrySample) ResourceUsage {
+	usage := ResourceUsage{
+		WallTimeSeconds:   s.time.Sub(start.time).Seconds(),
+		GCCount:           s.numGC - start.numGC,
+		GCPauseSeconds:    time.Duration(s.pauseTotalNs - start.pauseTotalNs).Seconds(),
+		Goroutines:        s.goroutines,
+		HeapAllocBytes:    s.heapAlloc,
+		rusageUnavailable: !s.rusageOK || !start.rusageOK,
+	}
+
+	if !usage.rusageUnavailable {
+		usage.UserCPUSeconds = (s.rusage.userCPU - start.rusage.userCPU).Seconds()
+		usage.SystemCPUSeconds = (s.rusage.systemCPU - start.rusage.systemCPU).Seconds()
+		usage.MaxRSSBytes = s.rusage.maxRSS
+	}
+
+	return usage
+}
+
+// ResourceUsage returns the resource usage of the process since the context was opened.
+// It returns false if telemetry is not enabled.
+func (c *Context[T]) ResourceUsage() (ResourceUsage, bool) {
+	if c.telemetryStart == nil {
+		return ResourceUsage{}, false
+	}
+
+	return sampleTelemetry().since(*c.telemetryStart), true
+}
+
+// telemetryMetadata returns the resource usage metadata of a materialization, or nil if telemetry is disabled.
+func (c *Context[T]) telemetryMetadata() map[string]any {
+	usage, ok := c.ResourceUsage()
+	if !ok {
+		return nil
+	}
+
+	return usage.Metadata()
+}
+
+// reportTelemetrySummary sends the resource usage of the run as custom message while
+// the context is closing, if telemetry is enabled.
+func (c *Context[T]) reportTelemetrySummary(ctx context.Context) error {
+	usage, ok := c.ResourceUsage()
+	if !ok {
+		return nil
+	}
+
+	return c.writeMessageIn(ctx, stateClosing, MethodReportCustomMessage, &CustomMessage{Payload: CustomEnvelope[ResourceUsage]{
+		Type:    usage.PayloadType(),
+		Payload: usage,
+	}})
+}
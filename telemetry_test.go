@@ -0,0 +1,95 @@
+package dagsterpipes
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestTelemetry(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		pc, channel := newTestContext(t, []string{"asset"})
+
+		if _, ok := pc.ResourceUsage(); ok {
+			t.Fatal("Expected no resource usage without telemetry")
+		}
+
+		if err := pc.ReportAssetMaterialization(&AssetMaterialization{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if err := pc.Close(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		params, _ := channel.Messages(MethodReportAssetMaterialization)[0]["params"].(map[string]any)
+		if metadata, _ := params["metadata"].(map[string]any); metadata[MetadataKeyWallTime] != nil {
+			t.Fatalf("Unexpected telemetry metadata: %v", metadata)
+		}
+
+		if len(channel.Messages(MethodReportCustomMessage)) != 0 {
+			t.Fatal("Unexpected telemetry summary")
+		}
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		pc, channel := newTestContext(t, []string{"asset"}, func(o *Options[map[string]any]) {
+			o.Telemetry.Enabled = true
+		})
+
+		runtime.GC()
+
+		if err := pc.ReportAssetMaterialization(&AssetMaterialization{
+			Metadata: map[string]any{MetadataKeyGoroutines: IntMetadata(0)},
+		}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if err := pc.Close(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		params, _ := channel.Messages(MethodReportAssetMaterialization)[0]["params"].(map[string]any)
+		metadata, _ := params["metadata"].(map[string]any)
+
+		expected := []string{MetadataKeyWallTime, MetadataKeyGCCount, MetadataKeyGCPause, MetadataKeyHeapAlloc}
+		if _, ok := getrusage(); ok {
+			expected = append(expected, MetadataKeyUserCPUTime, MetadataKeySystemCPUTime, MetadataKeyMaxRSS)
+		}
+
+		for _, key := range expected {
+			if _, ok := metadata[key].(map[string]any); !ok {
+				t.Errorf("Expected metadata %s, got %v", key, metadata)
+			}
+		}
+
+		if gcCount, _ := metadata[MetadataKeyGCCount].(map[string]any); gcCount["raw_value"].(float64) < 1 {
+			t.Errorf("Expected at least one GC, got %v", gcCount)
+		}
+
+		if goroutines, _ := metadata[MetadataKeyGoroutines].(map[string]any); goroutines["raw_value"] != float64(0) {
+			t.Errorf("Expected explicit metadata to take precedence, got %v", goroutines)
+		}
+
+		custom := channel.Messages(MethodReportCustomMessage)
+		if len(custom) != 1 {
+			t.Fatalf("Expected 1 telemetry summary, got %d", len(custom))
+		}
+
+		customParams, _ := custom[0]["params"].(map[string]any)
+		envelope, _ := customParams["payload"].(map[string]any)
+		payload, _ := envelope["payload"].(map[string]any)
+
+		if envelope["type"] != "resource_usage" || payload["wall_time_seconds"] == nil {
+			t.Fatalf("Unexpected telemetry summary: %v", envelope)
+		}
+
+		channel.mu.Lock()
+		summaryIndex := len(channel.messages) - 2
+		summary, closed := channel.messages[summaryIndex], channel.messages[summaryIndex+1]
+		channel.mu.Unlock()
+
+		if summary["method"] != string(MethodReportCustomMessage) || closed["method"] != string(MethodClosed) {
+			t.Fatalf("Expected summary right before closed, got %v and %v", summary["method"], closed["method"])
+		}
+	})
+}
@@ -1,6 +1,14 @@
 package dagsterpipes
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
 
 // MessageWriter defines an interface for creating and managing message channels.
 // It includes methods for opening a message channel and retrieving additional
@@ -15,23 +23,262 @@ type MessageWriter interface {
 	OpenedExtras() map[string]any
 }
 
+// MessageWriterFactory defines an interface for opening message channels
+// for the messages params shapes it claims.
+type MessageWriterFactory interface {
+	// Name returns the type of the channels opened by the factory, e.g. "file".
+	Name() string
+
+	// Keys returns the messages params keys understood by the factory.
+	Keys() []string
+
+	// Claims reports whether the factory can open a channel for the given params.
+	Claims(params *MessagesParams) bool
+
+	// Open initializes a MessageChannel using the provided parameters.
+	Open(params *MessagesParams) (MessageChannel, error)
+}
+
+// MessageWriterRegistry holds the message writer factories used to select
+// a message channel based on the messages params.
+type MessageWriterRegistry struct {
+	mu        sync.RWMutex           // Protects the registered factories.
+	factories []MessageWriterFactory // Registered factories in registration order.
+}
+
+// NewMessageWriterRegistry creates a new MessageWriterRegistry with the given factories.
+func NewMessageWriterRegistry(factories ...MessageWriterFactory) *MessageWriterRegistry {
+	r := &MessageWriterRegistry{}
+	for _, factory := range factories {
+		r.Register(factory)
+	}
+
+	return r
+}
+
+// Register adds a factory to the registry. Factories registered later take
+// precedence over earlier ones claiming the same params.
+func (r *MessageWriterRegistry) Register(factory MessageWriterFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.factories = append(r.factories, factory)
+}
+
+// Lookup returns the factory claiming the given params.
+// Returns a descriptive error listing the understood keys if no factory claims them.
+func (r *MessageWriterRegistry) Lookup(params *MessagesParams) (MessageWriterFactory, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := len(r.factories) - 1; i >= 0; i-- {
+		if r.factories[i].Claims(params) {
+			return r.factories[i], nil
+		}
+	}
+
+	understood := make([]string, 0, len(r.factories))
+	for _, factory := range r.factories {
+		understood = append(understood, fmt.Sprintf("%s %v", factory.Name(), factory.Keys()))
+	}
+
+	return nil, fmt.Errorf("unsupported messages params with keys %v, expected keys for one of: %s",
+		params.Keys(), strings.Join(understood, ", "))
+}
+
+// DefaultMessageWriterRegistry is the registry used by DefaultMessageWriter
+// if no registry is configured.
+var DefaultMessageWriterRegistry = NewMessageWriterRegistry(
+	&FileMessageWriterFactory{},
+	&StdioMessageWriterFactory{},
+	&BlobMessageWriterFactory{},
+)
+
+// RegisterMessageWriterFactory adds a factory to the DefaultMessageWriterRegistry.
+func RegisterMessageWriterFactory(factory MessageWriterFactory) {
+	DefaultMessageWriterRegistry.Register(factory)
+}
+
+// Capabilities advertised in the extras of the "opened" message.
+const (
+	// CapabilityLog indicates support for log messages.
+	CapabilityLog = "log"
+
+	// CapabilityAssetMaterialization indicates support for asset materialization reports.
+	CapabilityAssetMaterialization = "report_asset_materialization"
+
+	// CapabilityAssetCheck indicates support for asset check reports.
+	CapabilityAssetCheck = "report_asset_check"
+
+	// CapabilityCustomMessage indicates support for custom messages.
+	CapabilityCustomMessage = "report_custom_message"
+
+	// CapabilityChunking indicates that messages are uploaded in chunks.
+	CapabilityChunking = "chunking"
+)
+
+// CapabilityProvider can be implemented by a MessageChannel to advertise
+// additional capabilities in the "opened" message.
+type CapabilityProvider interface {
+	// Capabilities returns the capabilities supported by the channel.
+	Capabilities() []string
+}
+
 // DefaultMessageWriter is the default implementation of the MessageWriter interface.
-// It supports file-based message channels and serves as a fallback for unrecognized types.
-type DefaultMessageWriter struct{}
+// It selects the message channel from the factories of a MessageWriterRegistry.
+type DefaultMessageWriter struct {
+	Registry    *MessageWriterRegistry // Registry to select from; DefaultMessageWriterRegistry if nil.
+	channelType string                 // Type of the opened channel.
+	channel     MessageChannel         // The opened channel.
+}
 
-// Open initializes a file-based MessageChannel if a valid path is provided in the parameters.
-// Returns the created MessageChannel or an error if the path is missing or unsupported.
+// Open initializes the MessageChannel of the factory claiming the provided parameters.
+// Returns the created MessageChannel or an error if no factory claims the parameters.
 func (mw *DefaultMessageWriter) Open(params *MessagesParams) (MessageChannel, error) {
-	if params.Path != "" {
-		return NewFileMessageWriterChannel(params.Path)
+	registry := mw.Registry
+	if registry == nil {
+		registry = DefaultMessageWriterRegistry
+	}
+
+	factory, err := registry.Lookup(params)
+	if err != nil {
+		return nil, err
 	}
 
-	// TODO: Extend support for additional message writer types.
-	return nil, errors.New("no path provided")
+	channel, err := factory.Open(params)
+	if err != nil {
+		return nil, err
+	}
+
+	mw.channelType = factory.Name()
+	mw.channel = channel
+
+	return channel, nil
 }
 
 // OpenedExtras provides additional metadata for the opened message channel.
-// In the default implementation, this method returns an empty map.
+// It describes the library, the Go runtime and process, the channel type
+// and the supported capabilities.
 func (mw *DefaultMessageWriter) OpenedExtras() map[string]any {
-	return map[string]any{}
+	capabilities := []string{
+		CapabilityLog,
+		CapabilityAssetMaterialization,
+		CapabilityAssetCheck,
+		CapabilityCustomMessage,
+	}
+
+	if provider, ok := mw.channel.(CapabilityProvider); ok {
+		capabilities = append(capabilities, provider.Capabilities()...)
+	}
+
+	extras := map[string]any{
+		"library":         "dagster-pipes-go",
+		"library_version": Version(),
+		"go_version":      runtime.Version(),
+		"pid":             os.Getpid(),
+		"channel_type":    mw.channelType,
+		"capabilities":    capabilities,
+	}
+
+	if hostname, err := os.Hostname(); err == nil {
+		extras["hostname"] = hostname
+	}
+
+	return extras
+}
+
+// FileMessageWriterFactory opens channels for {"path": ...} params.
+// If the path is an existing directory, as used by DBFS message readers,
+// messages are written in chunks into that directory.
+type FileMessageWriterFactory struct{}
+
+// Name returns "file".
+func (f *FileMessageWriterFactory) Name() string {
+	return "file"
+}
+
+// Keys returns the understood keys.
+func (f *FileMessageWriterFactory) Keys() []string {
+	return []string{"path"}
+}
+
+// Claims reports whether the params contain a path.
+func (f *FileMessageWriterFactory) Claims(params *MessagesParams) bool {
+	return params.String("path") != ""
+}
+
+// Open opens a file or directory based message channel.
+func (f *FileMessageWriterFactory) Open(params *MessagesParams) (MessageChannel, error) {
+	path := params.String("path")
+
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return NewBlobMessageWriterChannel(&DirBlobStore{Dir: path}, ""), nil
+	}
+
+	return NewFileMessageWriterChannel(path)
+}
+
+// StdioMessageWriterFactory opens channels for {"stdio": "stderr" | "stdout"} params.
+type StdioMessageWriterFactory struct{}
+
+// Name returns "stdio".
+func (f *StdioMessageWriterFactory) Name() string {
+	return "stdio"
+}
+
+// Keys returns the understood keys.
+func (f *StdioMessageWriterFactory) Keys() []string {
+	return []string{"stdio"}
+}
+
+// Claims reports whether the params contain a stdio stream.
+func (f *StdioMessageWriterFactory) Claims(params *MessagesParams) bool {
+	return params.Has("stdio")
+}
+
+// Open opens a channel writing to the requested standard stream.
+func (f *StdioMessageWriterFactory) Open(params *MessagesParams) (MessageChannel, error) {
+	switch stream := params.String("stdio"); stream {
+	case "stderr":
+		return NewStreamMessageWriterChannel(os.Stderr), nil
+	case "stdout":
+		return NewStreamMessageWriterChannel(os.Stdout), nil
+	default:
+		return nil, fmt.Errorf("invalid stdio stream %q, expected stderr or stdout", stream)
+	}
+}
+
+// BlobMessageWriterFactory opens channels for {"bucket": ..., "key_prefix": ...} params,
+// uploading message chunks to the configured BlobStore.
+type BlobMessageWriterFactory struct {
+	Store    BlobStore     // Store the chunks are uploaded to.
+	Interval time.Duration // Interval between uploads; DefaultBlobUploadInterval if zero.
+}
+
+// Name returns "blob".
+func (f *BlobMessageWriterFactory) Name() string {
+	return "blob"
+}
+
+// Keys returns the understood keys.
+func (f *BlobMessageWriterFactory) Keys() []string {
+	return []string{"bucket", "key_prefix"}
+}
+
+// Claims reports whether the params contain a key prefix.
+func (f *BlobMessageWriterFactory) Claims(params *MessagesParams) bool {
+	return params.Has("key_prefix")
+}
+
+// Open opens a channel uploading message chunks below the key prefix.
+func (f *BlobMessageWriterFactory) Open(params *MessagesParams) (MessageChannel, error) {
+	if f.Store == nil {
+		return nil, errors.New("blob messages params require a BlobStore, register a BlobMessageWriterFactory with a store")
+	}
+
+	return NewBlobMessageWriterChannel(f.Store, params.String("key_prefix"), func(o *BlobMessageWriterChannelOptions) {
+		if f.Interval > 0 {
+			o.Interval = f.Interval
+		}
+	}), nil
 }
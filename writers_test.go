@@ -0,0 +1,207 @@
+package dagsterpipes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultMessageWriter(t *testing.T) {
+	t.Run("MessagesParams", func(t *testing.T) {
+		var params MessagesParams
+		if err := json.Unmarshal([]byte(`{"bucket": "b", "key_prefix": "p", "extra": 1}`), &params); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if params.String("bucket") != "b" || params.String("key_prefix") != "p" {
+			t.Fatalf("Expected arbitrary keys to be preserved, got %v", params.Params)
+		}
+
+		if got := strings.Join(params.Keys(), ","); got != "bucket,extra,key_prefix" {
+			t.Fatalf("Unexpected keys: %s", got)
+		}
+	})
+
+	t.Run("File", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "messages")
+
+		mw := &DefaultMessageWriter{}
+
+		channel, err := mw.Open(&MessagesParams{Path: path})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if _, ok := channel.(*FileMessageWriterChannel); !ok {
+			t.Fatalf("Expected file channel, got %T", channel)
+		}
+
+		if err := channel.Close(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Stdio", func(t *testing.T) {
+		mw := &DefaultMessageWriter{}
+
+		channel, err := mw.Open(&MessagesParams{Stdio: "stderr"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if _, ok := channel.(*StreamMessageWriterChannel); !ok {
+			t.Fatalf("Expected stream channel, got %T", channel)
+		}
+	})
+
+	t.Run("Blob", func(t *testing.T) {
+		dir := t.TempDir()
+
+		mw := &DefaultMessageWriter{Registry: NewMessageWriterRegistry(&BlobMessageWriterFactory{Store: &DirBlobStore{Dir: dir}})}
+
+		channel, err := mw.Open(&MessagesParams{Params: map[string]any{"bucket": "b", "key_prefix": "run/messages"}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		extras := mw.OpenedExtras()
+		if extras["channel_type"] != "blob" {
+			t.Fatalf("Expected channel type 'blob', got %v", extras["channel_type"])
+		}
+
+		if capabilities, _ := extras["capabilities"].([]string); !slices.Contains(capabilities, CapabilityChunking) {
+			t.Fatalf("Expected chunking capability, got %v", extras["capabilities"])
+		}
+
+		if err := channel.WriteMessage(Message{DagsterPipesVersion: ProtocolVersion, Method: MethodLog}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if err := channel.Close(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, "run", "messages", "1.json"))
+		if err != nil {
+			t.Fatalf("Expected uploaded chunk: %v", err)
+		}
+
+		if !strings.Contains(string(data), `"method":"log"`) {
+			t.Fatalf("Unexpected chunk content: %s", data)
+		}
+	})
+
+	t.Run("BlobInterval", func(t *testing.T) {
+		for _, interval := range []time.Duration{0, -time.Second} {
+			dir := t.TempDir()
+
+			channel := NewBlobMessageWriterChannel(&DirBlobStore{Dir: dir}, "messages", func(o *BlobMessageWriterChannelOptions) {
+				o.Interval = interval
+			})
+
+			if err := channel.WriteMessage(Message{DagsterPipesVersion: ProtocolVersion, Method: MethodLog}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if err := channel.Close(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if _, err := os.Stat(filepath.Join(dir, "messages", "1.json")); err != nil {
+				t.Fatalf("Expected uploaded chunk for interval %v: %v", interval, err)
+			}
+		}
+	})
+
+	t.Run("OpenedExtras", func(t *testing.T) {
+		pc, channel := newTestContext(t, []string{"asset"}, func(o *Options[map[string]any]) {
+			o.OpenedExtras = map[string]any{"team": "data"}
+		})
+		defer pc.Close()
+
+		opened := channel.Messages(MethodOpened)
+		if len(opened) != 1 {
+			t.Fatalf("Expected one opened message, got %d", len(opened))
+		}
+
+		params, _ := opened[0]["params"].(map[string]any)
+		if extras, _ := params["extras"].(map[string]any); extras["team"] != "data" {
+			t.Fatalf("Expected user extras, got %v", params["extras"])
+		}
+
+		extras := (&DefaultMessageWriter{}).OpenedExtras()
+		for _, key := range []string{"library_version", "go_version", "pid", "capabilities"} {
+			if _, ok := extras[key]; !ok {
+				t.Fatalf("Expected extras to contain %q, got %v", key, extras)
+			}
+		}
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		mw := &DefaultMessageWriter{}
+
+		_, err := mw.Open(&MessagesParams{Params: map[string]any{"unknown": true}})
+		if err == nil {
+			t.Fatal("Expected error for unsupported params")
+		}
+
+		for _, want := range []string{"[unknown]", "file [path]", "stdio [stdio]", "blob [bucket key_prefix]"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Fatalf("Expected error to contain %q, got %v", want, err)
+			}
+		}
+	})
+}
+
+func TestMessageChannelContext(t *testing.T) {
+	t.Run("Deadline", func(t *testing.T) {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		defer r.Close()
+		defer w.Close()
+
+		channel := NewStreamMessageWriterChannel(w)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		// Nobody reads the pipe, so the write blocks once the pipe buffer is full.
+		msg := Message{DagsterPipesVersion: ProtocolVersion, Method: MethodLog, Params: &Log{Message: strings.Repeat("x", 1<<17), Level: "INFO"}}
+
+		if err := channel.WriteMessageContext(ctx, msg); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected deadline exceeded, got %v", err)
+		}
+
+		// The aborted write left a truncated line, so the channel must reject further messages.
+		if err := channel.WriteMessage(Message{DagsterPipesVersion: ProtocolVersion, Method: MethodClosed}); !errors.Is(err, ErrPartialWrite) {
+			t.Fatalf("Expected partial write error, got %v", err)
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		channel := NewStreamMessageWriterChannel(&buf)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if err := channel.WriteMessageContext(ctx, Message{}); !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected canceled, got %v", err)
+		}
+
+		if buf.Len() != 0 {
+			t.Fatal("Expected nothing to be written")
+		}
+	})
+}